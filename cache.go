@@ -1,18 +1,54 @@
 package form
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"unicode"
 )
 
 type cachedField struct {
-	idx         int
-	name        string
-	isAnonymous bool
-	isOmitEmpty bool
+	idx           int
+	name          string
+	fieldName     string
+	isAnonymous   bool
+	isOmitEmpty   bool
+	isRequired    bool
+	defaultValues []string
+	constraints   []constraint
+	options       []string
+	timeLayouts   []string
+	timeUnit      uint8
+	byteFormat    ByteArrayFormat
+	bytesFormat   uint8
+	split         string
+}
+
+const (
+	timeUnitNone uint8 = iota
+	timeUnitSeconds
+	timeUnitMillis
+)
+
+// formats of byte slices, which are decoded from and encoded to a single value.
+const (
+	bytesRaw uint8 = iota
+	bytesBase64
+	bytesHex
+)
+
+// constraint is a check from a fields tag applied to the value after it is decoded.
+type constraint struct {
+	kind    error // ErrMin, ErrMax, ErrLen, ErrPattern or ErrOneOf
+	param   string
+	num     float64
+	pattern *regexp.Regexp
+	oneOf   []string
 }
 
 type cacheFields []cachedField
@@ -30,7 +66,8 @@ func (s cacheFields) Swap(i, j int) {
 }
 
 type cachedStruct struct {
-	fields cacheFields
+	fields  cacheFields
+	methods methods // of the struct type, for its hooks
 }
 
 // TagNameFunc allows for adding of a custom tag name parser
@@ -42,10 +79,14 @@ func newStructCacheMap() *structCacheMap {
 	return sc
 }
 
+// defaultFunc checks a default value from a tag can be converted to the field type.
+type defaultFunc func(structType reflect.Type, f *cachedField, typ reflect.Type, vals []string) error
+
 type structCacheMap struct {
-	m     atomic.Value // map[reflect.Type]*cachedStruct
-	lock  sync.Mutex
-	tagFn TagNameFunc
+	m         atomic.Value // map[reflect.Type]*cachedStruct
+	lock      sync.Mutex
+	tagFn     TagNameFunc
+	defaultFn defaultFunc
 }
 
 func (s *structCacheMap) Get(key reflect.Type) (value *cachedStruct, ok bool) {
@@ -74,15 +115,16 @@ func (s *structCacheMap) parseStruct(mode Mode, current reflect.Value, key refle
 		return cs
 	}
 
-	var idx int
 	var name string
-	var isOmitEmpty bool
+	var opts []string
+	var defaultValue string
+	var hasDefault bool
 	var fld reflect.StructField
 	typ := current.Type()
 	numFields := current.NumField()
-	cs = &cachedStruct{fields: make([]cachedField, 0, 4)} // init 4, betting most structs decoding into have at aleast 4 fields
+	cs = &cachedStruct{fields: make([]cachedField, 0, 4), methods: methodsOf(key)} // init 4, betting most structs decoding into have at aleast 4 fields
 	for i := 0; i < numFields; i++ {
-		isOmitEmpty = false
+		hasDefault = false
 		fld = typ.Field(i)
 		if fld.PkgPath != blank && !fld.Anonymous {
 			continue
@@ -98,17 +140,64 @@ func (s *structCacheMap) parseStruct(mode Mode, current reflect.Value, key refle
 			continue
 		}
 
-		// check for omitempty
-		if idx = strings.LastIndexByte(name, ','); idx != -1 {
-			isOmitEmpty = name[idx+1:] == "omitempty"
-			name = name[:idx]
+		name, opts = parseTag(name)
+		f := cachedField{idx: i, fieldName: fld.Name, isAnonymous: fld.Anonymous, options: opts}
+		for _, opt := range opts {
+			switch {
+			case opt == "omitempty":
+				f.isOmitEmpty = true
+			case opt == "required":
+				f.isRequired = true
+			case strings.HasPrefix(opt, "default="):
+				defaultValue, hasDefault = opt[len("default="):], true
+			case strings.HasPrefix(opt, "layout="):
+				s.checkTimeOption(fld, "layout")
+				f.timeLayouts = strings.Split(opt[len("layout="):], "|")
+			case opt == "unix":
+				s.checkTimeOption(fld, opt)
+				f.timeUnit = timeUnitSeconds
+			case opt == "unixmilli":
+				s.checkTimeOption(fld, opt)
+				f.timeUnit = timeUnitMillis
+			case opt == "hex":
+				if s.checkBytesOption(fld, opt, reflect.Array, reflect.Slice) == reflect.Array {
+					f.byteFormat = ByteArrayHex
+				} else {
+					f.bytesFormat = bytesHex
+				}
+			case opt == "uuid":
+				s.checkBytesOption(fld, opt, reflect.Array)
+				f.byteFormat = ByteArrayUUID
+			case opt == "base64":
+				s.checkBytesOption(fld, opt, reflect.Slice)
+				f.bytesFormat = bytesBase64
+			case strings.HasPrefix(opt, "split="):
+				if f.split = opt[len("split="):]; len(f.split) == 0 {
+					s.invalidTag("split requires a delimiter for field '%s'", fld.Name)
+				}
+
+				s.checkSplitOption(fld)
+			case opt == "raw":
+				s.checkBytesOption(fld, opt, reflect.Slice)
+				f.bytesFormat = bytesRaw
+			default:
+				if c, ok := s.parseConstraint(fld, opt); ok {
+					f.constraints = append(f.constraints, c)
+				}
+			}
 		}
 
 		if len(name) == 0 {
 			name = fld.Name
 		}
 
-		cs.fields = append(cs.fields, cachedField{idx: i, name: name, isAnonymous: fld.Anonymous, isOmitEmpty: isOmitEmpty})
+		f.name = name
+		// parsed last so the default is converted with the other options of the field
+		if hasDefault {
+			f.defaultValues = s.parseDefault(typ, fld, &f, defaultValue)
+		}
+
+		cs.fields = append(cs.fields, f)
 	}
 
 	sort.Sort(cs.fields)
@@ -117,3 +206,369 @@ func (s *structCacheMap) parseStruct(mode Mode, current reflect.Value, key refle
 
 	return cs
 }
+
+// parseDefault splits a default tag value, slices and arrays take multiple values separated by '|',
+// and checks it converts to the field type.
+func (s *structCacheMap) parseDefault(structType reflect.Type, fld reflect.StructField, f *cachedField, value string) []string {
+	typ := fld.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	vals := []string{value}
+	// byte slices, and byte arrays with a hex or uuid option, are a single value
+	if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) &&
+		(typ.Elem().Kind() != reflect.Uint8 || (typ.Kind() == reflect.Array && f.byteFormat == ByteArrayElements)) {
+		vals = strings.Split(value, "|")
+	}
+
+	if s.defaultFn != nil {
+		if err := s.defaultFn(structType, f, fld.Type, vals); err != nil {
+			s.invalidTag("invalid default '%s' for field '%s': %s", value, fld.Name, err)
+		}
+	}
+
+	return vals
+}
+
+// parseConstraint parses a min, max, len, pattern or oneof tag option
+// and checks it applies to the field type.
+func (s *structCacheMap) parseConstraint(fld reflect.StructField, opt string) (c constraint, ok bool) {
+	name, param, _ := strings.Cut(opt, "=")
+	switch name {
+	case "min":
+		c.kind = ErrMin
+	case "max":
+		c.kind = ErrMax
+	case "len":
+		c.kind = ErrLen
+	case "pattern":
+		c.kind = ErrPattern
+	case "oneof":
+		c.kind = ErrOneOf
+	default:
+		return
+	}
+
+	c.param = param
+	typ := fld.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var err error
+	switch c.kind {
+	case ErrMin, ErrMax, ErrLen:
+		if c.num, err = strconv.ParseFloat(param, 64); err != nil {
+			s.invalidTag("invalid %s '%s' for field '%s'", name, param, fld.Name)
+		}
+
+		if !hasLength(typ) && (c.kind == ErrLen || !isNumber(typ)) {
+			s.invalidTag("%s is not supported for field '%s' of type '%v'", name, fld.Name, fld.Type)
+		}
+	case ErrPattern, ErrOneOf:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+			for typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+		}
+
+		if typ.Kind() != reflect.String && !isNumber(typ) {
+			s.invalidTag("%s is not supported for field '%s' of type '%v'", name, fld.Name, fld.Type)
+		}
+
+		if c.kind == ErrOneOf {
+			c.oneOf = strings.Split(param, "|")
+		} else if c.pattern, err = regexp.Compile(param); err != nil {
+			s.invalidTag("invalid pattern '%s' for field '%s': %s", param, fld.Name, err)
+		}
+	}
+
+	return c, true
+}
+
+// match reports whether the value passes a pattern or oneof constraint.
+func (c *constraint) match(value string) bool {
+	if c.pattern != nil {
+		return c.pattern.MatchString(value)
+	}
+
+	for _, o := range c.oneOf {
+		if o == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkTimeOption checks a time option is only used on time.Time or sql.NullTime fields, or slices and arrays of them.
+func (s *structCacheMap) checkTimeOption(fld reflect.StructField, name string) {
+	typ := fld.Type
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+
+	if sqlNull(typ) {
+		typ = typ.Field(0).Type
+	}
+
+	if typ != timeType {
+		s.invalidTag("%s is not supported for field '%s' of type '%v'", name, fld.Name, fld.Type)
+	}
+}
+
+// checkBytesOption checks a byte option is only used on fields of byte arrays eg. [16]byte or byte slices,
+// of the given kinds, or maps, slices and arrays of them; it returns the kind found.
+func (s *structCacheMap) checkBytesOption(fld reflect.StructField, name string, kinds ...reflect.Kind) reflect.Kind {
+	typ := fld.Type
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Map ||
+		((typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() != reflect.Uint8) {
+		typ = typ.Elem()
+	}
+
+	for _, k := range kinds {
+		if typ.Kind() == k {
+			return k
+		}
+	}
+
+	s.invalidTag("%s is not supported for field '%s' of type '%v'", name, fld.Name, fld.Type)
+	return reflect.Invalid
+}
+
+// checkSplitOption checks the split option is only used on slice fields, other than byte slices.
+func (s *structCacheMap) checkSplitOption(fld reflect.StructField) {
+	typ := fld.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		s.invalidTag("split is not supported for field '%s' of type '%v'", fld.Name, fld.Type)
+	}
+}
+
+// invalidTag releases the lock and panics, a tag that can not be parsed is a programming error.
+func (s *structCacheMap) invalidTag(format string, args ...interface{}) {
+	s.lock.Unlock()
+	panic("form: " + fmt.Sprintf(format, args...))
+}
+
+// hasLength reports whether min, max and len constraints apply to the length of values of the type.
+func hasLength(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+
+	return false
+}
+
+// isNumber reports whether values of the type are numbers.
+func isNumber(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// tagOptions are the options recognised after the name in a struct tag.
+var tagOptions = map[string]struct{}{
+	"omitempty": {},
+	"required":  {},
+	"default":   {},
+	"min":       {},
+	"max":       {},
+	"len":       {},
+	"pattern":   {},
+	"oneof":     {},
+	"layout":    {},
+	"unix":      {},
+	"unixmilli": {},
+	"hex":       {},
+	"uuid":      {},
+	"base64":    {},
+	"raw":       {},
+	"split":     {},
+}
+
+// commaOptions are the options whose values may contain commas.
+var commaOptions = map[string]struct{}{
+	"default": {},
+	"pattern": {},
+	"split":   {},
+}
+
+// parseTag splits a struct tag into the name and its options.
+// A segment following an option in commaOptions belongs to that option's value,
+// unless it is itself a known option or of the form key=value, eg. `form:"tags,default=a,b"`.
+func parseTag(tag string) (name string, opts []string) {
+	name, rest, ok := strings.Cut(tag, ",")
+	for ok {
+		var seg string
+		seg, rest, ok = strings.Cut(rest, ",")
+		if len(opts) == 0 || isOption(seg) || !takesCommas(opts[len(opts)-1]) {
+			opts = append(opts, seg)
+		} else {
+			opts[len(opts)-1] += "," + seg
+		}
+	}
+
+	return
+}
+
+// takesCommas reports whether the option's value may contain commas.
+func takesCommas(opt string) bool {
+	key, _, hasValue := strings.Cut(opt, "=")
+	_, ok := commaOptions[key]
+	return ok && hasValue
+}
+
+// isOption reports whether a tag segment starts a new option,
+// either a known option or an unknown one of the form key=value.
+func isOption(seg string) bool {
+	key, _, hasValue := strings.Cut(seg, "=")
+	if _, ok := tagOptions[key]; ok {
+		return true
+	}
+
+	if !hasValue || len(key) == 0 {
+		return false
+	}
+
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// method is an interface the decoder or encoder checks values for.
+type method uint8
+
+const (
+	methodUnmarshaler method = iota
+	methodMarshaler
+	methodTextUnmarshaler
+	methodTextMarshaler
+	methodScanner
+	methodValuer
+	methodBeforeDecoder
+	methodAfterDecoder
+	methodValidator
+	methodBeforeEncoder
+)
+
+var methodTypes = [...]reflect.Type{
+	methodUnmarshaler:     unmarshalerType,
+	methodMarshaler:       marshalerType,
+	methodTextUnmarshaler: textUnmarshalerType,
+	methodTextMarshaler:   textMarshalerType,
+	methodScanner:         scannerType,
+	methodValuer:          valuerType,
+	methodBeforeDecoder:   beforeDecoderType,
+	methodAfterDecoder:    afterDecoderType,
+	methodValidator:       validatorType,
+	methodBeforeEncoder:   beforeEncoderType,
+}
+
+// methods is the set of interfaces a type implements, two bits per interface
+// for the type itself and for a pointer to it.
+type methods uint32
+
+func (m methods) value(i method) bool {
+	return m&(1<<(2*i)) != 0
+}
+
+func (m methods) pointer(i method) bool {
+	return m&(2<<(2*i)) != 0
+}
+
+// methodCache holds the methods of each type, which never change,
+// so the interfaces are not checked again for every value.
+var methodCache = newMethodCacheMap()
+
+func newMethodCacheMap() *methodCacheMap {
+	mc := new(methodCacheMap)
+	mc.m.Store(make(map[reflect.Type]methods))
+	return mc
+}
+
+type methodCacheMap struct {
+	m    atomic.Value // map[reflect.Type]methods
+	lock sync.Mutex
+}
+
+// methodsOf returns the methods of the type, parsing them on first use.
+func methodsOf(typ reflect.Type) methods {
+	// unnamed and predeclared types other than structs, which can embed, have no methods
+	if typ.Kind() != reflect.Struct && typ.PkgPath() == "" {
+		return 0
+	}
+
+	if m, ok := methodCache.m.Load().(map[reflect.Type]methods)[typ]; ok {
+		return m
+	}
+
+	methodCache.lock.Lock()
+	defer methodCache.lock.Unlock()
+
+	m := methodCache.m.Load().(map[reflect.Type]methods)
+	if ms, ok := m[typ]; ok {
+		return ms
+	}
+
+	ms := parseMethods(typ)
+	nm := make(map[reflect.Type]methods, len(m)+1)
+	for k, v := range m {
+		nm[k] = v
+	}
+
+	nm[typ] = ms
+	methodCache.m.Store(nm)
+	return ms
+}
+
+// parseMethods checks the interfaces implemented by the type.
+// Pointers and interfaces have none as their values are checked once dereferenced,
+// time.Time is not a text (un)marshaler and the database/sql Null types are not Scanners or Valuers
+// as they have their own handling, and text methods promoted from an embedded field are ignored.
+func parseMethods(typ reflect.Type) (m methods) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return 0
+	}
+
+	ptr := reflect.PointerTo(typ)
+	for i, iface := range methodTypes {
+		if !ptr.Implements(iface) {
+			continue
+		}
+
+		switch method(i) {
+		case methodTextUnmarshaler, methodTextMarshaler:
+			if typ == timeType || !declares(typ, iface) {
+				continue
+			}
+		case methodScanner, methodValuer:
+			if sqlNull(typ) {
+				continue
+			}
+		}
+
+		m |= 2 << (2 * i)
+		if typ.Implements(iface) {
+			m |= 1 << (2 * i)
+		}
+	}
+
+	return m
+}
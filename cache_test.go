@@ -1,8 +1,11 @@
 package form
 
 import (
+	"database/sql"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 )
@@ -27,3 +30,48 @@ func TestDecoderMultipleSimultaniousParseStructRequests(t *testing.T) {
 
 	close(proceed)
 }
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		name string
+		opts []string
+	}{
+		{tag: "id", name: "id"},
+		{tag: "id,omitempty,lookup", name: "id", opts: []string{"omitempty", "lookup"}},
+		{tag: "name,required,trim", name: "name", opts: []string{"required", "trim"}},
+		{tag: "fields,default=id,name", name: "fields", opts: []string{"default=id,name"}},
+		{tag: "fields,default=id,name,required", name: "fields", opts: []string{"default=id,name", "required"}},
+		{tag: "slug,pattern=^[a-z]{1,8}$,case=lower", name: "slug", opts: []string{"pattern=^[a-z]{1,8}$", "case=lower"}},
+		{tag: "ids,split=,,layout=2006-01-02", name: "ids", opts: []string{"split=,", "layout=2006-01-02"}},
+		{tag: "ids,min=1,lookup", name: "ids", opts: []string{"min=1", "lookup"}},
+		{tag: ",omitempty", opts: []string{"omitempty"}},
+	}
+
+	for _, tt := range tests {
+		name, opts := parseTag(tt.tag)
+		assert.Equal(t, name, tt.name)
+		assert.Equal(t, opts, tt.opts)
+	}
+}
+
+func TestMethodsOf(t *testing.T) {
+	type event struct {
+		time.Time
+		Name string
+	}
+
+	m := methodsOf(reflect.TypeOf(big.Int{}))
+	assert.Equal(t, m.pointer(methodTextUnmarshaler), true)
+	assert.Equal(t, m.pointer(methodTextMarshaler), true)
+	assert.Equal(t, m.value(methodTextMarshaler), false)
+
+	m = methodsOf(reflect.TypeOf(time.Time{}))
+	assert.Equal(t, m.pointer(methodTextUnmarshaler), false)
+	assert.Equal(t, m.pointer(methodTextMarshaler), false)
+
+	assert.Equal(t, methodsOf(reflect.TypeOf(event{})), methods(0))
+	assert.Equal(t, methodsOf(reflect.TypeOf(sql.NullString{})).pointer(methodScanner), false)
+	assert.Equal(t, methodsOf(reflect.TypeOf(&big.Int{})), methods(0))
+	assert.Equal(t, methodsOf(reflect.TypeOf("")), methods(0))
+}
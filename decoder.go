@@ -1,44 +1,563 @@
 package form
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"maps"
 	"net/url"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
-	errArraySize           = "Array size of '%d' is larger than the maximum currently set on the decoder of '%d'. To increase this limit please see, SetMaxArraySize(size uint)"
-	errMissingEndBracket   = "Invalid formatting for key '%s' missing ']' bracket"
-	errMissingStartBracket = "Invalid formatting for key '%s' missing '[' bracket"
+	errArraySize = "Array size of '%d' is larger than the maximum currently set on the decoder of '%d'. To increase this limit please see, SetMaxArraySize(size uint)"
 )
 
 type decoder struct {
-	d         *Decoder
-	dm        dataMap
-	errs      DecodeErrors
-	values    url.Values
+	d        *Decoder
+	dm       dataMap
+	parsed   bool
+	elements int
+	errs     DecodeErrors
+	values   url.Values
+	used     map[string]struct{}
+	// source are the values passed in, before keys using empty brackets were added under the keys they append to,
+	// derived the keys added for each of those keys, and copied whether values is a copy, as source is never modified
+	source    url.Values
+	derived   map[string][]string
+	copied    bool
 	maxKeyLen int
 	namespace []byte
+	path      []byte
+	embedded  bool
+	ctx       context.Context
+	// field and structType are the struct field being decoded, for FieldInfo
+	field      *cachedField
+	structType reflect.Type
+	// visits counts the fields decoded from each key shared by embedded structs in AnonymousEmbed mode
+	visits map[string]int
+}
+
+// reset prepares the decoder for decoding the values into the type, which is nil when not known.
+func (d *decoder) reset(values url.Values, strict bool, typ reflect.Type) {
+	d.source = values
+	d.values, d.derived = d.appendValues(values, typ)
+	d.copied = d.derived != nil
+	clear(d.visits)
+	d.dm = d.dm[0:0]
+	d.parsed = false
+	d.elements = 0
+	d.field = nil
+	d.structType = nil
+	if !strict {
+		d.used = nil
+	} else if d.used == nil {
+		d.used = make(map[string]struct{})
+	}
+}
+
+// present reports whether any value exists for the namespace, either directly or nested within it.
+func (d *decoder) present(namespace []byte) bool {
+	if _, ok := d.values[string(namespace)]; ok {
+		return true
+	}
+
+	l := len(namespace)
+	for k := range d.values {
+		if len(k) <= l || k[:l] != string(namespace) {
+			continue
+		}
+
+		if k[l] == '[' || (len(d.d.namespacePrefix) > 0 && strings.HasPrefix(k[l:], d.d.namespacePrefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTime parses a time using the layout or unix options of the field being decoded,
+// falling back to the layouts set on the Decoder.
+func (d *decoder) parseTime(s string) (t time.Time, err error) {
+	loc := d.d.timeLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	layouts := d.d.timeLayouts
+	if d.field != nil {
+		switch d.field.timeUnit {
+		case timeUnitSeconds, timeUnitMillis:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return t, err
+			}
+
+			if d.field.timeUnit == timeUnitSeconds {
+				return time.Unix(n, 0).In(loc), nil
+			}
+
+			return time.UnixMilli(n).In(loc), nil
+		}
+
+		if d.field.timeLayouts != nil {
+			layouts = d.field.timeLayouts
+		}
+	}
+
+	for _, layout := range layouts {
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return
+		}
+	}
+
+	return
+}
+
+// parseDuration parses a duration in time.ParseDuration syntax,
+// or a plain number in the unit set on the Decoder.
+func (d *decoder) parseDuration(s string) (time.Duration, error) {
+	dur, err := time.ParseDuration(s)
+	if err == nil || d.d.durationUnit == 0 {
+		return dur, err
+	}
+
+	if n, e := strconv.ParseInt(s, 10, 64); e == nil {
+		if dur = time.Duration(n) * d.d.durationUnit; dur/d.d.durationUnit == time.Duration(n) {
+			return dur, nil
+		}
+
+		return 0, errors.New("time: invalid duration " + strconv.Quote(s) + ", out of range")
+	}
+
+	if f, e := strconv.ParseFloat(s, 64); e == nil {
+		if f *= float64(d.d.durationUnit); f >= -(1<<63) && f < 1<<63 {
+			return time.Duration(f), nil
+		}
+	}
+
+	return 0, err
+}
+
+// byteArrayFormat returns the format of the array type using the hex or uuid option of the field being decoded,
+// falling back to the format set on the Decoder; arrays of other than bytes always use ByteArrayElements.
+func (d *decoder) byteArrayFormat(typ reflect.Type) ByteArrayFormat {
+	if typ.Elem().Kind() != reflect.Uint8 {
+		return ByteArrayElements
+	}
+
+	if d.field != nil && d.field.byteFormat != ByteArrayElements {
+		return d.field.byteFormat
+	}
+
+	return d.d.byteArrayFormat
+}
+
+// context returns the context passed to DecodeContext.
+func (d *decoder) context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+
+	return d.ctx
+}
+
+// fieldInfo describes the struct field being decoded for a context-aware custom type function.
+func (d *decoder) fieldInfo(namespace []byte, vals []string) FieldInfo {
+	info := FieldInfo{Namespace: string(namespace), Values: vals}
+	if d.field != nil {
+		info.Field = d.structType.Field(d.field.idx)
+		info.Options = d.field.options
+	}
+
+	return info
+}
+
+// setValues sets the value from values other than those passed in, eg. a field's default values
+// or the elements split from a value, converted as though they were passed in under the namespace.
+func (d *decoder) setValues(current reflect.Value, namespace []byte, vals []string) (set bool) {
+	dec := d.d.dataPool.Get().(*decoder)
+	dec.reset(url.Values{string(namespace): vals}, false, nil)
+	dec.path = append(dec.path[:0], d.path...)
+	dec.ctx, dec.field, dec.structType = d.ctx, d.field, d.structType
+	set = dec.setFieldByType(current, namespace, 0)
+	for k, err := range dec.errs {
+		d.setError([]byte(k), err)
+	}
+
+	dec.errs = nil
+	dec.ctx = nil
+	d.d.dataPool.Put(dec)
+	return
+}
+
+// splitDelimiter returns the delimiter slice values are split on, from the split option of the field being decoded
+// falling back to the delimiter set on the Decoder; blank when values are not split.
+func (d *decoder) splitDelimiter() string {
+	if d.field != nil && len(d.field.split) > 0 {
+		return d.field.split
+	}
+
+	return d.d.splitDelimiter
+}
+
+// beforeDecode calls BeforeDecode on the struct, if implemented,
+// and decodes the struct from the values as the hook left them.
+func (d *decoder) beforeDecode(v reflect.Value, m methods, namespace []byte) {
+	h, ok := implementer(v, m, methodBeforeDecoder)
+	if !ok {
+		return
+	}
+
+	before := d.subValues(namespace, 0, false)
+	values := make(url.Values, len(before))
+	for k, vals := range before {
+		values[k] = slices.Clone(vals)
+	}
+
+	h.(BeforeDecoder).BeforeDecode(values)
+
+	changed := false
+	ns := string(namespace)
+	for rel := range before {
+		if _, ok := values[rel]; !ok {
+			k := d.namespaceKey(ns, rel)
+			// removed by the hook, so not unknown in strict mode
+			d.markUsed([]byte(k))
+			d.copyValues()
+			delete(d.values, k)
+			changed = true
+		}
+	}
+
+	for rel, vals := range values {
+		if old, ok := before[rel]; !ok || !slices.Equal(old, vals) {
+			d.copyValues()
+			d.values[d.namespaceKey(ns, rel)] = vals
+			changed = true
+		}
+	}
+
+	if changed {
+		// a new data map, as the one being parsed may still be iterated by the enclosing slices and maps
+		d.dm = nil
+		d.parsed = false
+	}
+}
+
+// copyValues copies the values before they are modified, so the values passed to Decode never are.
+func (d *decoder) copyValues() {
+	if !d.copied {
+		d.values = maps.Clone(d.values)
+		d.copied = true
+	}
+}
+
+// namespaceKey returns the key of the values relative to the namespace, reversing subValues.
+func (d *decoder) namespaceKey(ns, rel string) string {
+	if len(ns) == 0 {
+		return rel
+	}
+
+	if len(rel) == 0 || rel[0] == '[' {
+		return ns + rel
+	}
+
+	// the suffix follows the field name, which ends at the first unescaped '[' or '.'
+	i := 0
+	for ; i < len(rel) && rel[i] != '[' && rel[i] != '.'; i++ {
+		if rel[i] == '\\' && d.d.escapeKeys {
+			i++
+		}
+	}
+
+	if i > len(rel) {
+		i = len(rel)
+	}
+
+	return ns + d.d.namespacePrefix + rel[:i] + d.d.namespaceSuffix + rel[i:]
+}
+
+// afterDecode calls AfterDecode and then Validate on the struct, if implemented, and sets the errors they return.
+func (d *decoder) afterDecode(v reflect.Value, m methods, namespace []byte) {
+	if h, ok := implementer(v, m, methodAfterDecoder); ok {
+		if err := h.(AfterDecoder).AfterDecode(); err != nil {
+			d.setHookError(v.Type(), namespace, err)
+		}
+	}
+
+	if h, ok := implementer(v, m, methodValidator); ok {
+		if err := h.(Validator).Validate(); err != nil {
+			d.setHookError(v.Type(), namespace, err)
+		}
+	}
+}
+
+// setHookError sets an error returned by AfterDecode or Validate,
+// rewriting the Go path of field errors into the form namespace.
+func (d *decoder) setHookError(typ reflect.Type, namespace []byte, err error) {
+	if fe, ok := err.(*FieldError); ok {
+		e := *fe
+		e.Namespace = string(namespace)
+		if len(fe.Field) > 0 {
+			e.Namespace = d.fieldNamespace(typ, namespace, fe.Field)
+			if len(d.path) > 0 && fe.Field[0] != '[' {
+				e.Field = string(d.path) + "." + fe.Field
+			} else {
+				e.Field = string(d.path) + fe.Field
+			}
+		} else {
+			e.Field = string(d.path)
+		}
+
+		d.setError([]byte(e.Namespace), &e)
+		return
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			d.setHookError(typ, namespace, err)
+		}
+
+		return
+	}
+
+	d.setError(namespace, &FieldError{Namespace: string(namespace), Field: string(d.path), Err: err})
+}
+
+// fieldNamespace returns the form namespace of a Go field path within the struct type at the namespace,
+// parts of the path that do not match a field are used as is.
+func (d *decoder) fieldNamespace(typ reflect.Type, namespace []byte, path string) string {
+	ns := append(make([]byte, 0, len(namespace)+len(path)), namespace...)
+	for len(path) > 0 {
+		for typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			continue
+		case '[':
+			i := strings.IndexByte(path, ']') + 1
+			if i == 0 {
+				ns = append(ns, path...)
+				path = path[len(path):]
+				continue
+			}
+
+			ns = d.d.keySyntax.appendKey(ns, path[1:i-1])
+			path = path[i:]
+			if typ != nil {
+				switch typ.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					typ = typ.Elem()
+				default:
+					typ = nil
+				}
+			}
+
+			continue
+		}
+
+		i := strings.IndexAny(path, ".[")
+		if i == -1 {
+			i = len(path)
+		}
+
+		name := path[:i]
+		path = path[i:]
+		if typ != nil && typ.Kind() == reflect.Struct {
+			name, typ = d.findField(typ, name)
+		} else {
+			typ = nil
+		}
+
+		if d.d.escapeKeys {
+			name = escapeKey(name)
+		}
+
+		if len(ns) == 0 {
+			ns = append(ns, name...)
+		} else {
+			ns = append(ns, d.d.namespacePrefix...)
+			ns = append(ns, name...)
+			ns = append(ns, d.d.namespaceSuffix...)
+		}
+	}
+
+	return string(ns)
+}
+
+// sharedIndex returns the index of the value of a key shared by the fields of a struct and the structs it embeds
+// for the field, as the Encoder in AnonymousEmbed mode appends the value of each in turn, false when there is none for it.
+// Fields of any later visit are only decoded from a single value, as the decoder reads every value of the key for the others.
+func (d *decoder) sharedIndex(typ reflect.Type, namespace []byte) (int, bool) {
+	if d.visits == nil {
+		d.visits = make(map[string]int)
+	}
+
+	idx := d.visits[string(namespace)]
+	d.visits[string(namespace)]++
+	if idx == 0 {
+		return 0, true
+	}
+
+	return idx, idx < len(d.values[string(namespace)]) && d.singleValue(typ)
+}
+
+// consumes reports whether a value of the type decodes from the values of its own namespace,
+// rather than those nested within it, eg. a slice of structs does not.
+func (d *decoder) consumes(typ reflect.Type, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Ptr, reflect.Map:
+		return false
+	case reflect.Struct:
+		return valueStruct(typ)
+	case reflect.Slice, reflect.Array:
+		return d.singleValue(typ) || d.singleValue(typ.Elem())
+	}
+
+	return true
+}
+
+// singleValue reports whether a value of the type is decoded from a single value of its key.
+func (d *decoder) singleValue(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if d.d.decodesWhole(typ) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	case reflect.Array, reflect.Map, reflect.Interface:
+		return false
+	case reflect.Struct:
+		return valueStruct(typ)
+	}
+
+	return true
+}
+
+// findField returns the form name and type of the Go field of the struct type,
+// looking within embedded structs the decoder flattens, the type is nil when the field is not found.
+func (d *decoder) findField(typ reflect.Type, fieldName string) (string, reflect.Type) {
+	s, ok := d.d.structCache.Get(typ)
+	if !ok {
+		s = d.d.structCache.parseStruct(d.d.mode, reflect.New(typ).Elem(), typ, d.d.tagName)
+	}
+
+	for _, f := range s.fields {
+		if f.fieldName == fieldName {
+			return f.name, typ.Field(f.idx).Type
+		}
+	}
+
+	for _, f := range s.fields {
+		if !f.isAnonymous {
+			continue
+		}
+
+		ft := typ.Field(f.idx).Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct {
+			if name, t := d.findField(ft, fieldName); t != nil {
+				return name, t
+			}
+		}
+	}
+
+	return fieldName, nil
+}
+
+// checkConstraints sets an error for the first tag constraint the decoded value fails.
+func (d *decoder) checkConstraints(current reflect.Value, namespace []byte, constraints []constraint) {
+	v, kind := ExtractType(current)
+	if kind == reflect.Ptr {
+		return
+	}
+
+	for i := range constraints {
+		c := &constraints[i]
+		switch c.kind {
+		case ErrMin, ErrMax, ErrLen:
+			var n float64
+			var value string
+			switch kind {
+			case reflect.String:
+				value = v.String()
+				n = float64(utf8.RuneCountInString(value))
+			case reflect.Slice, reflect.Array, reflect.Map:
+				n = float64(v.Len())
+				value = strconv.Itoa(v.Len())
+			default:
+				value, _ = formatScalar(v)
+				n = scalarNumber(v)
+			}
+
+			if (c.kind == ErrMin && n < c.num) || (c.kind == ErrMax && n > c.num) || (c.kind == ErrLen && n != c.num) {
+				d.constraintError(namespace, c, value, v.Type())
+				return
+			}
+		default:
+			if kind != reflect.Slice && kind != reflect.Array {
+				if value, ok := formatScalar(v); ok && !c.match(value) {
+					d.constraintError(namespace, c, value, v.Type())
+					return
+				}
+
+				continue
+			}
+
+			for j := 0; j < v.Len(); j++ {
+				if value, ok := formatScalar(v.Index(j)); ok && !c.match(value) {
+					d.constraintError(namespace, c, value, v.Type())
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *decoder) constraintError(namespace []byte, c *constraint, value string, typ reflect.Type) {
+	fe := d.fieldError(namespace, c.kind, value, typ, nil)
+	fe.Param = c.param
+	d.setError(namespace, fe)
 }
 
 func (d *decoder) getMapKey(key string, current reflect.Value, namespace []byte) (err error) {
 	v, kind := ExtractType(current)
 	if d.d.customTypeFuncs != nil {
 		if cf, ok := d.d.customTypeFuncs[v.Type()]; ok {
-			val, er := cf([]string{key})
-			if er != nil {
-				err = er
-				return
+			if er := cf(d, v, namespace, []string{key}); er != nil {
+				err = d.fieldError(namespace, nil, key, v.Type(), er)
 			}
 
-			v.Set(reflect.ValueOf(val))
 			return
 		}
 	}
 
+	if tu, ok := textUnmarshaler(v, methodsOf(v.Type())); ok {
+		if er := unmarshalText(tu, v, key); er != nil {
+			err = d.fieldError(namespace, ErrInvalidValue, key, v.Type(), er)
+		}
+
+		return
+	}
+
 	switch kind {
 	case reflect.Interface:
 		// If interface would have been set on the struct before decoding,
@@ -55,85 +574,114 @@ func (d *decoder) getMapKey(key string, current reflect.Value, namespace []byte)
 	case reflect.Uint, reflect.Uint64:
 		u64, e := strconv.ParseUint(key, 10, 64)
 		if e != nil {
-			return fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidUnsignedInteger, key, v.Type(), e)
 		}
 		v.SetUint(u64)
 	case reflect.Uint8:
 		u64, e := strconv.ParseUint(key, 10, 8)
 		if e != nil {
-			return fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidUnsignedInteger, key, v.Type(), e)
 		}
 		v.SetUint(u64)
 	case reflect.Uint16:
 		u64, e := strconv.ParseUint(key, 10, 16)
 		if e != nil {
-			return fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidUnsignedInteger, key, v.Type(), e)
 		}
 		v.SetUint(u64)
 	case reflect.Uint32:
 		u64, e := strconv.ParseUint(key, 10, 32)
 		if e != nil {
-			return fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidUnsignedInteger, key, v.Type(), e)
 		}
 		v.SetUint(u64)
 	case reflect.Int, reflect.Int64:
+		if v.Type() == durationType {
+			dur, e := d.parseDuration(key)
+			if e != nil {
+				return d.fieldError(namespace, ErrInvalidDuration, key, v.Type(), e)
+			}
+			v.SetInt(int64(dur))
+			return
+		}
+
 		i64, e := strconv.ParseInt(key, 10, 64)
 		if e != nil {
-			return fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidInteger, key, v.Type(), e)
 		}
 		v.SetInt(i64)
 	case reflect.Int8:
 		i64, e := strconv.ParseInt(key, 10, 8)
 		if e != nil {
-			return fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidInteger, key, v.Type(), e)
 		}
 		v.SetInt(i64)
 	case reflect.Int16:
 		i64, e := strconv.ParseInt(key, 10, 16)
 		if e != nil {
-			return fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidInteger, key, v.Type(), e)
 		}
 		v.SetInt(i64)
 	case reflect.Int32:
 		i64, e := strconv.ParseInt(key, 10, 32)
 		if e != nil {
-			return fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidInteger, key, v.Type(), e)
 		}
 		v.SetInt(i64)
 	case reflect.Float32:
 		f, e := strconv.ParseFloat(key, 32)
 		if e != nil {
-			return fmt.Errorf("Invalid Float Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidFloat, key, v.Type(), e)
 		}
 		v.SetFloat(f)
 	case reflect.Float64:
 		f, e := strconv.ParseFloat(key, 64)
 		if e != nil {
-			return fmt.Errorf("Invalid Float Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidFloat, key, v.Type(), e)
 		}
 		v.SetFloat(f)
+	case reflect.Complex64:
+		c, e := strconv.ParseComplex(key, 64)
+		if e != nil {
+			return d.fieldError(namespace, ErrInvalidComplex, key, v.Type(), e)
+		}
+		v.SetComplex(c)
+	case reflect.Complex128:
+		c, e := strconv.ParseComplex(key, 128)
+		if e != nil {
+			return d.fieldError(namespace, ErrInvalidComplex, key, v.Type(), e)
+		}
+		v.SetComplex(c)
 	case reflect.Bool:
 		b, e := parseBool(key)
 		if e != nil {
-			return fmt.Errorf("Invalid Boolean Value '%s' Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+			return d.fieldError(namespace, ErrInvalidBool, key, v.Type(), e)
 		}
 		v.SetBool(b)
+	case reflect.Array:
+		if d.byteArrayFormat(v.Type()) == ByteArrayElements {
+			return d.fieldError(namespace, ErrUnsupportedMapKey, key, v.Type(), nil)
+		}
+
+		if e := parseByteArray(v, key); e != nil {
+			return d.fieldError(namespace, ErrInvalidValue, key, v.Type(), e)
+		}
 	default:
-		return fmt.Errorf("Unsupported Map Key '%s', Type '%v' Namespace '%s'", key, v.Type(), string(namespace))
+		return d.fieldError(namespace, ErrUnsupportedMapKey, key, v.Type(), nil)
 	}
 
 	return
 }
 
 func (d *decoder) parseMapData() {
-	if len(d.dm) > 0 {
-		// already parsed
+	if d.parsed {
 		return
 	}
 
-	var i, idx, l int
+	var i, idx int
 	var rd *recursiveData
-	var isNum, insideBracket bool
+	var isNum bool
+	d.parsed = true
 	d.maxKeyLen = 0
 	d.dm = d.dm[0:0]
 	for k := range d.values {
@@ -141,75 +689,478 @@ func (d *decoder) parseMapData() {
 			d.maxKeyLen = len(k)
 		}
 
-		for i = 0; i < len(k); i++ {
-			switch k[i] {
-			case '[':
-				idx = i
-				insideBracket = true
-				isNum = true
-			case ']':
-				if !insideBracket {
-					log.Panicf(errMissingStartBracket, k)
-				}
+		// validate first so a malformed key never ends up partially in the data map
+		if err := checkBrackets(k, d.d.escapeKeys); err != nil {
+			d.setError([]byte(k), err)
+			continue
+		}
+
+		// keys using empty brackets are decoded through the keys they append to, see appendValues
+		if d.derived != nil && emptyBrackets(k, d.d.escapeKeys) != -1 {
+			continue
+		}
+
+		if d.d.keySyntax == KeySyntaxDot {
+			d.parseDotKey(k)
+			continue
+		}
+
+		for i = 0; i < len(k); i++ {
+			switch k[i] {
+			case '\\':
+				if d.d.escapeKeys {
+					// the escaped character is part of the key
+					i++
+					isNum = false
+				}
+			case '[':
+				idx = i
+				isNum = true
+			case ']':
+				rd = d.aliasData(k[:idx])
+				// is map + key
+				ke := key{
+					ivalue:      -1,
+					value:       k[idx+1 : i],
+					searchValue: k[idx : i+1],
+				}
+
+				if d.d.escapeKeys {
+					ke.value = unescapeKey(ke.value)
+				}
+
+				// is key is number, most likely array key, keep track of just in case an array/slice
+				if isNum {
+					// the value has been checked to only contain digits ahead of time,
+					// so an error means it is empty or overflows an int,
+					// in which case it is reported when used as an index
+					var err error
+					ke.ivalue, err = strconv.Atoi(ke.value)
+					if err != nil {
+						ke.ivalue = -1
+					}
+
+					if ke.ivalue > rd.sliceLen {
+						rd.sliceLen = ke.ivalue
+
+					}
+				}
+
+				rd.keys = append(rd.keys, ke)
+			default:
+				// checking if not a number, 0-9 is 48-57 in byte, see for yourself fmt.Println('0', '1', '2', '3', '4', '5', '6', '7', '8', '9')
+				if k[i] > 57 || k[i] < 48 {
+					isNum = false
+				}
+			}
+		}
+	}
+}
+
+// appendValues returns the values with the keys using empty brackets eg. Tags[]=a&Tags[]=b and Items[].Name=x
+// added under the keys they append to eg. Tags and Items[0].Name; each value of a key with empty brackets
+// before its end appends a new element, after any at an explicit index.
+// The original keys are kept so fields named with the brackets still match them,
+// derived holds the keys added for each of them and is nil when there are none.
+func (d *decoder) appendValues(values url.Values, typ reflect.Type) (url.Values, map[string][]string) {
+	var keys []string
+	for k := range values {
+		if emptyBrackets(k, d.d.escapeKeys) != -1 {
+			keys = append(keys, k)
+		}
+	}
+
+	if keys == nil {
+		return values, nil
+	}
+
+	// in order so values appended to the same key are too
+	sort.Strings(keys)
+	out := make(url.Values, len(values)+len(keys))
+	for k, vals := range values {
+		// clipped so appending never writes to the values passed in
+		out[k] = vals[:len(vals):len(vals)]
+	}
+
+	derived := make(map[string][]string, len(keys))
+	bases := make(map[string]int)
+	for _, k := range keys {
+		derived[k] = d.expandBrackets(typ, values, out, bases, k, values[k], nil)
+	}
+
+	return out, derived
+}
+
+// expandBrackets adds the values of the key to out, replacing its empty brackets, and returns the keys added.
+// Each value is added at its own index following the highest explicit index of the key before the brackets,
+// except that trailing empty brackets of a key without explicit indexes are removed as repeated values already append to slices.
+// With KeySyntaxMixed the struct fields of the element named in brackets are written as fields eg. Items[][Name] as Items[0].Name.
+func (d *decoder) expandBrackets(typ reflect.Type, values, out url.Values, bases map[string]int, key string, vals []string, added []string) []string {
+	syntax := d.d.keySyntax
+	i := emptyBrackets(key, d.d.escapeKeys)
+	if i == -1 {
+		out[key] = append(out[key], vals...)
+		return append(added, key)
+	}
+
+	prefix, rest := key[:i], key[i+2:]
+	base, ok := bases[prefix]
+	if !ok {
+		base = nextIndex(syntax, values, prefix)
+		bases[prefix] = base
+	}
+
+	// merged into the plain key, which fills from index 0, the values would overwrite the explicit indexes
+	if len(rest) == 0 && base == 0 {
+		return d.expandBrackets(typ, values, out, bases, prefix, vals, added)
+	}
+
+	if syntax == KeySyntaxMixed && typ != nil {
+		rest = d.dotFields(d.segmentType(d.keyType(typ, prefix), "", true), rest)
+	}
+
+	for j, v := range vals {
+		k := string(syntax.appendIndex([]byte(prefix), base+j)) + rest
+		added = d.expandBrackets(typ, values, out, bases, k, []string{v}, added)
+	}
+
+	return added
+}
+
+// dotFields rewrites the bracket segments of the rest of a key following a value of the type,
+// up to any empty brackets, that name struct fields to the '.' of KeySyntaxMixed eg. [Name] to .Name.
+func (d *decoder) dotFields(typ reflect.Type, rest string) string {
+	var b strings.Builder
+	for typ != nil && len(rest) > 0 {
+		name, bracket, n := nextSegment(rest, d.d.escapeKeys)
+		if n == -1 || (bracket && len(name) == 0) {
+			break
+		}
+
+		ft := d.segmentType(typ, name, bracket)
+		if bracket && ft != nil && structType(typ) != nil {
+			b.WriteByte('.')
+			b.WriteString(name)
+		} else {
+			b.WriteString(rest[:n])
+		}
+
+		typ, rest = ft, rest[n:]
+	}
+
+	b.WriteString(rest)
+	return b.String()
+}
+
+// keyType returns the type the KeySyntaxMixed key decodes into within a value of the type,
+// or nil when the key does not lead to a value.
+func (d *decoder) keyType(typ reflect.Type, key string) reflect.Type {
+	for typ != nil && len(key) > 0 {
+		name, bracket, n := nextSegment(key, d.d.escapeKeys)
+		if n == -1 {
+			return nil
+		}
+
+		typ, key = d.segmentType(typ, name, bracket), key[n:]
+	}
+
+	return typ
+}
+
+// segmentType returns the type of a segment of a key within a value of the type,
+// a struct field, or a slice, array or map element, or nil when the segment does not lead to a value.
+func (d *decoder) segmentType(typ reflect.Type, name string, bracket bool) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if valueStruct(typ) || d.d.decodesWhole(typ) {
+			return nil
+		}
+
+		return d.fieldType(typ, name)
+	case reflect.Slice, reflect.Array:
+		if bracket {
+			return typ.Elem()
+		}
+	case reflect.Map:
+		return typ.Elem()
+	}
+
+	return nil
+}
+
+// fieldType returns the type of the field of the struct type with the form name, as written in keys,
+// looking within embedded structs the decoder flattens, or nil when there is none.
+func (d *decoder) fieldType(typ reflect.Type, name string) reflect.Type {
+	s, ok := d.d.structCache.Get(typ)
+	if !ok {
+		s = d.d.structCache.parseStruct(d.d.mode, reflect.New(typ).Elem(), typ, d.d.tagName)
+	}
+
+	for i := range s.fields {
+		f := &s.fields[i]
+		ft := typ.Field(f.idx).Type
+		if f.isAnonymous && d.d.embedAnonymous {
+			if st := structType(ft); st != nil {
+				if t := d.fieldType(st, name); t != nil {
+					return t
+				}
+			}
+
+			if !d.d.separateAnonymous {
+				continue
+			}
+		}
+
+		fname := f.name
+		if d.d.escapeKeys {
+			fname = escapeKey(fname)
+		}
+
+		if fname == name {
+			return ft
+		}
+	}
+
+	return nil
+}
+
+// structType returns the struct type, following pointers, or nil when it is not a struct.
+func structType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return typ
+}
+
+// nextSegment returns the first segment of a KeySyntaxMixed key, whether it is in brackets,
+// and the length of the key it takes up including the brackets or leading '.', n is -1 for a missing ']'.
+func nextSegment(key string, escaped bool) (name string, bracket bool, n int) {
+	if key[0] == '[' {
+		for i := 1; i < len(key); i++ {
+			switch {
+			case key[i] == '\\' && escaped:
+				i++
+			case key[i] == ']':
+				return key[1:i], true, i + 1
+			}
+		}
+
+		return "", true, -1
+	}
+
+	start := 0
+	if key[0] == '.' {
+		start = 1
+	}
+
+	i := start
+	for ; i < len(key) && key[i] != '.' && key[i] != '['; i++ {
+		if key[i] == '\\' && escaped {
+			i++
+		}
+	}
+
+	if i > len(key) {
+		i = len(key)
+	}
+
+	return key[start:i], false, i
+}
+
+// emptyBrackets returns the index of the first empty brackets in the key, or -1 if there are none,
+// skipping characters escaped with a backslash when escaped is true.
+func emptyBrackets(k string, escaped bool) int {
+	for i := 0; i < len(k)-1; i++ {
+		switch {
+		case k[i] == '\\' && escaped:
+			i++
+		case k[i] == '[' && k[i+1] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// nextIndex returns the index following the highest explicit index of the prefix in the keys eg. 2 for Items[1].Name,
+// or Items.1.Name with KeySyntaxDot.
+func nextIndex(syntax KeySyntax, values url.Values, prefix string) (next int) {
+	start, end := byte('['), byte(']')
+	if syntax == KeySyntaxDot {
+		start, end = '.', '.'
+	}
+
+	for k := range values {
+		if len(k) <= len(prefix)+1 || !strings.HasPrefix(k, prefix) || k[len(prefix)] != start {
+			continue
+		}
+
+		index := k[len(prefix)+1:]
+		if i := strings.IndexByte(index, end); i != -1 {
+			index = index[:i]
+		} else if syntax != KeySyntaxDot {
+			continue
+		}
+
+		if n, err := strconv.Atoi(index); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	return
+}
+
+// aliasData returns the data of the alias, adding it to the data map if not yet present.
+func (d *decoder) aliasData(alias string) (rd *recursiveData) {
+	if rd = d.findAlias(alias); rd != nil {
+		return
+	}
+
+	l := len(d.dm) + 1
+	if l > cap(d.dm) {
+		dm := make(dataMap, l)
+		copy(dm, d.dm)
+		rd = new(recursiveData)
+		dm[len(d.dm)] = rd
+		d.dm = dm
+	} else {
+		l = len(d.dm)
+		d.dm = d.dm[:l+1]
+		rd = d.dm[l]
+		rd.sliceLen = 0
+		rd.keys = rd.keys[0:0]
+	}
+
+	rd.alias = alias
+	return
+}
+
+// parseDotKey adds each segment of a KeySyntaxDot key to the data map as a key of the namespace before it,
+// eg. for Items.0.Name the index 0 of Items and the key Name of Items.0.
+func (d *decoder) parseDotKey(k string) {
+	// a leading '.' does not start a segment
+	for idx := 1; idx < len(k); idx++ {
+		if k[idx] == '\\' && d.d.escapeKeys {
+			idx++
+			continue
+		}
+
+		if k[idx] != '.' {
+			continue
+		}
+
+		end := idx + 1
+		for end < len(k) && k[end] != '.' {
+			if k[end] == '\\' && d.d.escapeKeys {
+				end++
+			}
+
+			end++
+		}
+
+		end = min(end, len(k))
 
-				if rd = d.findAlias(k[:idx]); rd == nil {
-					l = len(d.dm) + 1
-					if l > cap(d.dm) {
-						dm := make(dataMap, l)
-						copy(dm, d.dm)
-						rd = new(recursiveData)
-						dm[len(d.dm)] = rd
-						d.dm = dm
-					} else {
-						l = len(d.dm)
-						d.dm = d.dm[:l+1]
-						rd = d.dm[l]
-						rd.sliceLen = 0
-						rd.keys = rd.keys[0:0]
-					}
+		rd := d.aliasData(k[:idx])
+		ke := key{
+			ivalue:      -1,
+			value:       k[idx+1 : end],
+			searchValue: k[idx:end],
+		}
 
-					rd.alias = k[:idx]
-				}
+		if d.d.escapeKeys {
+			ke.value = unescapeKey(ke.value)
+		}
 
-				// is map + key
-				ke := key{
-					ivalue:      -1,
-					value:       k[idx+1 : i],
-					searchValue: k[idx : i+1],
-				}
+		if isDigits(ke.value) {
+			var err error
+			if ke.ivalue, err = strconv.Atoi(ke.value); err != nil {
+				ke.ivalue = -1
+			}
 
-				// is key is number, most likely array key, keep track of just in case an array/slice
-				if isNum {
-					// no need to check for error, it will always pass
-					// as we have done the checking to ensure
-					// the value is a number ahead of time
-					var err error
-					ke.ivalue, err = strconv.Atoi(ke.value)
-					if err != nil {
-						ke.ivalue = -1
-					}
+			if ke.ivalue > rd.sliceLen {
+				rd.sliceLen = ke.ivalue
+			}
+		}
 
-					if ke.ivalue > rd.sliceLen {
-						rd.sliceLen = ke.ivalue
+		rd.keys = append(rd.keys, ke)
+		idx = end - 1
+	}
+}
 
-					}
-				}
+// isDigits reports whether the string is made of only the digits 0-9.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
 
-				rd.keys = append(rd.keys, ke)
-				insideBracket = false
-			default:
-				// checking if not a number, 0-9 is 48-57 in byte, see for yourself fmt.Println('0', '1', '2', '3', '4', '5', '6', '7', '8', '9')
-				if insideBracket && (k[i] > 57 || k[i] < 48) {
-					isNum = false
-				}
+	return true
+}
+
+// checkBrackets ensures every ']' in the key is preceded by a '[' and every '[' is closed,
+// skipping characters escaped with a backslash when escaped is true.
+func checkBrackets(k string, escaped bool) error {
+	var insideBracket bool
+	for i := 0; i < len(k); i++ {
+		switch k[i] {
+		case '\\':
+			if escaped {
+				i++
 			}
+		case '[':
+			insideBracket = true
+		case ']':
+			if !insideBracket {
+				return &KeyError{Key: k, Err: ErrMissingStartBracket}
+			}
+
+			insideBracket = false
 		}
+	}
+
+	// if still inside bracket, that means no ending bracket was ever specified
+	if insideBracket {
+		return &KeyError{Key: k, Err: ErrMissingEndBracket}
+	}
+
+	return nil
+}
+
+// indexError returns the error for a key that can not be used as a slice or array index.
+func indexError(kind string, namespace []byte, kv key) error {
+	digits := kv.value
+	if len(digits) > 1 && digits[0] == '-' {
+		digits = digits[1:]
+	}
 
-		// if still inside bracket, that means no ending bracket was ever specified
-		if insideBracket {
-			log.Panicf(errMissingEndBracket, k)
+	for i := 0; i < len(digits); i++ {
+		if digits[i] > 57 || digits[i] < 48 {
+			return fmt.Errorf("invalid %s index '%s'", kind, kv.value)
 		}
 	}
+
+	switch {
+	case len(digits) == 0:
+		return fmt.Errorf("invalid %s index '%s'", kind, kv.value)
+	case len(digits) < len(kv.value):
+		return &KeyError{Key: string(namespace) + kv.searchValue, Err: ErrNegativeIndex}
+	default:
+		return &KeyError{Key: string(namespace) + kv.searchValue, Err: ErrIndexOverflow}
+	}
 }
 
 func (d *decoder) findAlias(ns string) *recursiveData {
@@ -221,6 +1172,18 @@ func (d *decoder) findAlias(ns string) *recursiveData {
 	return nil
 }
 
+// fieldError creates a FieldError for the namespace and the current Go field path.
+func (d *decoder) fieldError(namespace []byte, kind error, value string, typ reflect.Type, err error) *FieldError {
+	return &FieldError{
+		Namespace: string(namespace),
+		Field:     string(d.path),
+		Value:     value,
+		Type:      typ,
+		Kind:      kind,
+		Err:       err,
+	}
+}
+
 func (d *decoder) setError(namespace []byte, err error) {
 	if d.errs == nil {
 		d.errs = make(DecodeErrors)
@@ -228,20 +1191,210 @@ func (d *decoder) setError(namespace []byte, err error) {
 	d.errs[string(namespace)] = err
 }
 
+// subValues returns the values within the namespace, relative to it, for an Unmarshaler;
+// the values of the namespace itself are passed under a blank key starting at idx.
+func (d *decoder) subValues(namespace []byte, idx int, consume bool) url.Values {
+	var sub url.Values
+	ns := string(namespace)
+	prefix, suffix := d.d.namespacePrefix, d.d.namespaceSuffix
+	for k, vals := range d.values {
+		if !strings.HasPrefix(k, ns) {
+			continue
+		}
+
+		rel := k[len(ns):]
+		switch {
+		case len(rel) == 0:
+			if idx >= len(vals) {
+				continue
+			}
+
+			vals = vals[idx:]
+		case len(ns) == 0:
+		case len(prefix) > 0 && strings.HasPrefix(rel, prefix):
+			// reverse what traverseStruct does for a nested field name
+			rel = rel[len(prefix):]
+			if len(suffix) > 0 {
+				if i := strings.Index(rel, suffix); i != -1 {
+					rel = rel[:i] + rel[i+len(suffix):]
+				}
+			}
+		case rel[0] == '[':
+		default:
+			// a different field which shares the prefix eg. "PriceList" for "Price"
+			continue
+		}
+
+		if sub == nil {
+			sub = make(url.Values)
+		}
+
+		sub[rel] = vals
+		if consume {
+			d.markUsed([]byte(k))
+		}
+	}
+
+	return sub
+}
+
+// markUsed records that the values of the namespace were consumed by a field,
+// it is only tracked in strict mode.
+func (d *decoder) markUsed(namespace []byte) {
+	if d.used != nil {
+		d.used[string(namespace)] = struct{}{}
+	}
+}
+
+// checkUnused sets an error for every key that was not consumed during decoding.
+func (d *decoder) checkUnused() {
+	for k := range d.source {
+		if _, ok := d.used[k]; !ok && !d.derivedUsed(k) {
+			d.setError([]byte(k), &FieldError{Namespace: k, Kind: ErrUnknownKey})
+		}
+	}
+
+	clear(d.used)
+}
+
+// derivedUsed reports whether any of the keys added for a key using empty brackets was consumed.
+func (d *decoder) derivedUsed(k string) bool {
+	for _, dk := range d.derived[k] {
+		if _, ok := d.used[dk]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allocate accounts for n newly allocated slice or map elements,
+// returning false and setting an error if it would exceed Limits.MaxElements.
+func (d *decoder) allocate(namespace []byte, n int) bool {
+	limit := d.d.limits.MaxElements
+	if limit <= 0 {
+		return true
+	}
+
+	if n > limit-d.elements {
+		d.setError(namespace, &LimitError{Key: string(namespace), Limit: limit, Err: ErrTooManyElements})
+		return false
+	}
+
+	d.elements += n
+	return true
+}
+
+// arrayOverflow reports values that did not fit into an array of the given length,
+// either as a warning or, when enabled on the Decoder, as an error.
+func (d *decoder) arrayOverflow(namespace []byte, length int, vals []string) {
+	if d.d.arrayOverflowError {
+		err := d.fieldError(namespace, ErrArrayOverflow, "", nil, nil)
+		err.Param = strconv.Itoa(length)
+		d.setError(namespace, err)
+		return
+	}
+
+	if d.d.warningFunc != nil {
+		d.d.warningFunc(DecodeWarning{
+			Namespace: string(namespace),
+			Kind:      WarningArrayOverflow,
+			Values:    vals,
+		})
+	}
+}
+
+// droppedValues returns the values of the namespace and those nested within it, sorted by key,
+// eg. the values of an array element beyond its length.
+func (d *decoder) droppedValues(namespace []byte) []string {
+	sub := d.subValues(namespace, 0, false)
+	vals := make([]string, 0, len(sub))
+	for _, k := range slices.Sorted(maps.Keys(sub)) {
+		vals = append(vals, sub[k]...)
+	}
+
+	return vals
+}
+
+// reported reports whether an earlier key of the alias has the index, as each nested key adds it eg. Arr[3].Name and Arr[3].Zip.
+func reported(keys []key, ivalue int) bool {
+	for i := range keys {
+		if keys[i].ivalue == ivalue {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx int) (set bool) {
 	var err error
 	v, kind := ExtractType(current)
 	arr, ok := d.values[string(namespace)]
+	if ok && d.used != nil && d.consumes(v.Type(), kind) {
+		d.markUsed(namespace)
+	}
+
 	if d.d.customTypeFuncs != nil {
-		if ok {
+		if ok && idx < len(arr) {
 			if cf, ok := d.d.customTypeFuncs[v.Type()]; ok {
-				val, err := cf(arr[idx:])
-				if err != nil {
-					d.setError(namespace, err)
+				d.markUsed(namespace)
+				if err := cf(d, v, namespace, arr[idx:]); err != nil {
+					d.setError(namespace, d.fieldError(namespace, nil, arr[idx], v.Type(), err))
+					return
+				}
+
+				set = true
+				return
+			}
+		}
+	}
+
+	m := methodsOf(v.Type())
+	if um, ok := formUnmarshaler(v, m); ok {
+		sub := d.subValues(namespace, idx, true)
+		if len(sub) == 0 {
+			return
+		}
+
+		if err = um.UnmarshalForm(sub); err != nil {
+			d.setError(namespace, d.fieldError(namespace, nil, blank, v.Type(), err))
+			return
+		}
+
+		set = true
+		return
+	}
+
+	if ok && idx < len(arr) && m != 0 {
+		if tu, ok := textUnmarshaler(v, m); ok {
+			d.markUsed(namespace)
+			if len(arr[idx]) == 0 {
+				return
+			}
+
+			if err = unmarshalText(tu, v, arr[idx]); err != nil {
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidValue, arr[idx], v.Type(), err))
+				return
+			}
+
+			set = true
+			return
+		}
+
+		if d.d.sqlBridge {
+			if sc, ok := scanner(v, m); ok {
+				d.markUsed(namespace)
+				var src interface{}
+				if len(arr[idx]) > 0 {
+					src = arr[idx]
+				}
+
+				if err = sc.Scan(src); err != nil {
+					d.setError(namespace, d.fieldError(namespace, ErrInvalidValue, arr[idx], v.Type(), err))
 					return
 				}
 
-				v.Set(reflect.ValueOf(val))
 				set = true
 				return
 			}
@@ -275,7 +1428,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var u64 uint64
 		if u64, err = strconv.ParseUint(arr[idx], 10, 64); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidUnsignedInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -288,7 +1441,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var u64 uint64
 		if u64, err = strconv.ParseUint(arr[idx], 10, 8); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidUnsignedInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -301,7 +1454,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var u64 uint64
 		if u64, err = strconv.ParseUint(arr[idx], 10, 16); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidUnsignedInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -314,7 +1467,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var u64 uint64
 		if u64, err = strconv.ParseUint(arr[idx], 10, 32); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Unsigned Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidUnsignedInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -325,9 +1478,21 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			return
 		}
 
+		if v.Type() == durationType {
+			var dur time.Duration
+			if dur, err = d.parseDuration(arr[idx]); err != nil {
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidDuration, arr[idx], v.Type(), err))
+				return
+			}
+
+			v.SetInt(int64(dur))
+			set = true
+			return
+		}
+
 		var i64 int64
 		if i64, err = strconv.ParseInt(arr[idx], 10, 64); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -340,7 +1505,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var i64 int64
 		if i64, err = strconv.ParseInt(arr[idx], 10, 8); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -353,7 +1518,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var i64 int64
 		if i64, err = strconv.ParseInt(arr[idx], 10, 16); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -366,7 +1531,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var i64 int64
 		if i64, err = strconv.ParseInt(arr[idx], 10, 32); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Integer Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidInteger, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -379,7 +1544,7 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var f float64
 		if f, err = strconv.ParseFloat(arr[idx], 32); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Float Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidFloat, arr[idx], v.Type(), err))
 			return
 		}
 
@@ -392,12 +1557,30 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var f float64
 		if f, err = strconv.ParseFloat(arr[idx], 64); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Float Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidFloat, arr[idx], v.Type(), err))
 			return
 		}
 
 		v.SetFloat(f)
 		set = true
+	case reflect.Complex64, reflect.Complex128:
+		if !ok || idx == len(arr) || len(arr[idx]) == 0 {
+			return
+		}
+
+		bitSize := 128
+		if kind == reflect.Complex64 {
+			bitSize = 64
+		}
+
+		var c complex128
+		if c, err = strconv.ParseComplex(arr[idx], bitSize); err != nil {
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidComplex, arr[idx], v.Type(), err))
+			return
+		}
+
+		v.SetComplex(c)
+		set = true
 	case reflect.Bool:
 		if !ok || idx == len(arr) {
 			return
@@ -405,25 +1588,61 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 
 		var b bool
 		if b, err = parseBool(arr[idx]); err != nil {
-			d.setError(namespace, fmt.Errorf("Invalid Boolean Value '%s' Type '%v' Namespace '%s'", arr[idx], v.Type(), string(namespace)))
+			d.setError(namespace, d.fieldError(namespace, ErrInvalidBool, arr[idx], v.Type(), err))
 			return
 		}
 
 		v.SetBool(b)
 		set = true
 	case reflect.Slice:
+		// byte slices are a single value
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if !ok || idx == len(arr) {
+				return
+			}
+
+			var format uint8
+			if d.field != nil {
+				format = d.field.bytesFormat
+			}
+
+			b, err := parseBytes(arr[idx], format)
+			if err != nil {
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidValue, arr[idx], v.Type(), err))
+				return
+			}
+
+			v.SetBytes(b)
+			set = true
+			return
+		}
+
 		d.parseMapData()
+		// values split into elements are decoded on their own, so errors are set under the element's index
+		var split bool
+		if sep := d.splitDelimiter(); ok && len(sep) > 0 {
+			arr, split = splitValues(arr, sep), true
+		}
+
 		// slice elements could be mixed eg. number and non-numbers Value[0]=[]string{"10"} and Value=[]string{"10","20"}
 		if ok && len(arr) > 0 {
 			var ol int
 			var varr reflect.Value
 			l := len(arr)
 			if v.IsNil() {
+				if !d.allocate(namespace, len(arr)) {
+					return
+				}
+
 				varr = reflect.MakeSlice(v.Type(), len(arr), len(arr))
 			} else {
 				ol = v.Len()
 				l += ol
 				if v.Cap() <= l {
+					if !d.allocate(namespace, l) {
+						return
+					}
+
 					varr = reflect.MakeSlice(v.Type(), l, l)
 				} else {
 					// preserve predefined capacity, possibly for reuse after decoding
@@ -433,14 +1652,27 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 				reflect.Copy(varr, v)
 			}
 
+			pl := len(d.path)
 			for i := ol; i < l; i++ {
 				newVal := reflect.New(v.Type().Elem()).Elem()
+				d.path = append(strconv.AppendInt(append(d.path[:pl], '['), int64(i), 10), ']')
+				if split {
+					ns := d.d.keySyntax.appendIndex(namespace[:len(namespace):len(namespace)], i)
+					if d.setValues(newVal, ns, arr[i-ol:i-ol+1]) {
+						set = true
+						varr.Index(i).Set(newVal)
+					}
+
+					continue
+				}
+
 				if d.setFieldByType(newVal, namespace, i-ol) {
 					set = true
 					varr.Index(i).Set(newVal)
 				}
 			}
 
+			d.path = d.path[:pl]
 			v.Set(varr)
 		}
 
@@ -448,21 +1680,25 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		if rd := d.findAlias(string(namespace)); rd != nil {
 			var kv key
 			var varr reflect.Value
-			sl := rd.sliceLen + 1
-			// checking below for maxArraySize, but if array exists and already
+			// checking for maxArraySize, but if array exists and already
 			// has sufficient capacity allocated then we do not check as the code
-			// obviously allows a capacity greater than the maxArraySize.
+			// obviously allows a capacity greater than the maxArraySize;
+			// comparing against the highest index also guards against sliceLen + 1 overflowing.
+			if rd.sliceLen >= d.d.maxArraySize && (v.IsNil() || (v.Len() <= rd.sliceLen && v.Cap() <= rd.sliceLen)) {
+				d.setError(namespace, fmt.Errorf(errArraySize, uint(rd.sliceLen)+1, d.d.maxArraySize))
+				return
+			}
+
+			sl := rd.sliceLen + 1
 			if v.IsNil() {
-				if sl > d.d.maxArraySize {
-					d.setError(namespace, fmt.Errorf(errArraySize, sl, d.d.maxArraySize))
+				if !d.allocate(namespace, sl) {
 					return
 				}
 
 				varr = reflect.MakeSlice(v.Type(), sl, sl)
 			} else if v.Len() < sl {
 				if v.Cap() <= sl {
-					if sl > d.d.maxArraySize {
-						d.setError(namespace, fmt.Errorf(errArraySize, sl, d.d.maxArraySize))
+					if !d.allocate(namespace, sl) {
 						return
 					}
 
@@ -476,20 +1712,24 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 				varr = v
 			}
 
+			pl := len(d.path)
 			for i := 0; i < len(rd.keys); i++ {
 				kv = rd.keys[i]
 				newVal := reflect.New(varr.Type().Elem()).Elem()
 				if kv.ivalue == -1 {
-					d.setError(namespace, fmt.Errorf("invalid slice index '%s'", kv.value))
+					d.setError(namespace, indexError("slice", namespace, kv))
 					continue
 				}
 
+				d.path = append(append(append(d.path[:pl], '['), kv.value...), ']')
 				if d.setFieldByType(newVal, append(namespace, kv.searchValue...), 0) {
 					set = true
 					varr.Index(kv.ivalue).Set(newVal)
 				}
 			}
 
+			d.path = d.path[:pl]
+
 			if !set {
 				return
 			}
@@ -497,33 +1737,50 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			v.Set(varr)
 		}
 	case reflect.Array:
+		if d.byteArrayFormat(v.Type()) != ByteArrayElements {
+			if !ok || idx == len(arr) || len(arr[idx]) == 0 {
+				return
+			}
+
+			if err = parseByteArray(v, arr[idx]); err != nil {
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidValue, arr[idx], v.Type(), err))
+				return
+			}
+
+			set = true
+			return
+		}
+
 		d.parseMapData()
 		// array elements could be mixed eg. number and non-numbers Value[0]=[]string{"10"} and Value=[]string{"10","20"}
 		if ok && len(arr) > 0 {
 			var varr reflect.Value
 			l := len(arr)
-			overCapacity := v.Len() < l
-			if overCapacity {
+			if v.Len() < l {
 				// more values than array capacity,
 				// ignore values over capacity as it's possible some would just want
-				// to grab the first x number of elements;
-				// in the future strict mode logic should return an error
-				fmt.Println("warning number of post form array values is larger than array capacity, ignoring overflow values")
+				// to grab the first x number of elements
+				d.arrayOverflow(namespace, v.Len(), arr[v.Len():])
 			}
+
 			varr = reflect.Indirect(reflect.New(reflect.ArrayOf(v.Len(), v.Type().Elem())))
 			reflect.Copy(varr, v)
 			if v.Len() < len(arr) {
 				l = v.Len()
 			}
 
+			pl := len(d.path)
 			for i := 0; i < l; i++ {
 				newVal := reflect.New(v.Type().Elem()).Elem()
+				d.path = append(strconv.AppendInt(append(d.path[:pl], '['), int64(i), 10), ']')
 				if d.setFieldByType(newVal, namespace, i) {
 					set = true
 					varr.Index(i).Set(newVal)
 				}
 			}
 
+			d.path = d.path[:pl]
+
 			v.Set(varr)
 		}
 
@@ -531,24 +1788,27 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		if rd := d.findAlias(string(namespace)); rd != nil {
 			var kv key
 			var varr reflect.Value
-			overCapacity := rd.sliceLen >= v.Len()
-			if overCapacity {
-				// more values than array capacity, ignore values over capacity as it's possible some would just want
-				// to grab the first x number of elements; in the future strict mode logic should return an error
-				fmt.Println("warning number of post form array values is larger than array capacity, ignoring overflow values")
-			}
-
 			varr = reflect.Indirect(reflect.New(reflect.ArrayOf(v.Len(), v.Type().Elem())))
 			reflect.Copy(varr, v)
+			pl := len(d.path)
 			for i := 0; i < len(rd.keys); i++ {
 				kv = rd.keys[i]
+				d.path = append(append(append(d.path[:pl], '['), kv.value...), ']')
 				if kv.ivalue >= v.Len() {
+					// index over capacity, ignore as it's possible some would just want
+					// to grab the first x number of elements
+					if !reported(rd.keys[:i], kv.ivalue) {
+						ns := append(namespace, kv.searchValue...)
+						d.arrayOverflow(ns, v.Len(), d.droppedValues(ns))
+					}
+
 					continue
 				}
 
 				newVal := reflect.New(varr.Type().Elem()).Elem()
 				if kv.ivalue == -1 {
-					d.setError(namespace, fmt.Errorf("invalid array index '%s'", kv.value))
+					d.path = d.path[:pl]
+					d.setError(namespace, indexError("array", namespace, kv))
 					continue
 				}
 
@@ -558,6 +1818,8 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 				}
 			}
 
+			d.path = d.path[:pl]
+
 			if !set {
 				return
 			}
@@ -584,21 +1846,37 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			mp = v
 		}
 
+		pl := len(d.path)
 		for i := 0; i < len(rd.keys); i++ {
 			newVal := reflect.New(typ.Elem()).Elem()
 			mk = reflect.New(typ.Key()).Elem()
 			kv = rd.keys[i]
+			d.path = d.path[:pl]
 			if err := d.getMapKey(kv.value, mk, namespace); err != nil {
 				d.setError(namespace, err)
 				continue
 			}
 
+			d.path = append(append(append(d.path, '['), kv.value...), ']')
 			if d.setFieldByType(newVal, append(namespace, kv.searchValue...), 0) {
+				if !mp.MapIndex(mk).IsValid() {
+					if limit := d.d.limits.MaxMapEntries; limit > 0 && mp.Len() >= limit {
+						d.setError(namespace, &LimitError{Key: string(namespace), Limit: limit, Err: ErrTooManyMapEntries})
+						break
+					}
+
+					if !d.allocate(namespace, 1) {
+						break
+					}
+				}
+
 				set = true
 				mp.SetMapIndex(mk, newVal)
 			}
 		}
 
+		d.path = d.path[:pl]
+
 		if !set || existing {
 			return
 		}
@@ -606,15 +1884,16 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		v.Set(mp)
 	case reflect.Struct:
 		typ := v.Type()
-		// if we get here then no custom time function declared so use RFC3339 by default
+		// if we get here then no custom time function declared so use the field or decoder layouts, RFC3339 by default
 		if typ == timeType {
-			if !ok || len(arr[idx]) == 0 {
+			if !ok || idx == len(arr) || len(arr[idx]) == 0 {
 				return
 			}
 
-			t, err := time.Parse(time.RFC3339, arr[idx])
+			t, err := d.parseTime(arr[idx])
 			if err != nil {
-				d.setError(namespace, err)
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidTime, arr[idx], typ, err))
+				return
 			}
 
 			v.Set(reflect.ValueOf(t))
@@ -622,6 +1901,39 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			return
 		}
 
+		if sqlNull(typ) {
+			if !ok || idx == len(arr) {
+				return
+			}
+
+			// an empty value is a NULL
+			if len(arr[idx]) == 0 {
+				v.Set(reflect.Zero(typ))
+				set = true
+				return
+			}
+
+			if set = d.setFieldByType(v.Field(0), namespace, idx); set {
+				v.Field(1).SetBool(true)
+			}
+
+			return
+		}
+
+		if typ == urlType || typ == mailAddressType {
+			if !ok || idx == len(arr) || len(arr[idx]) == 0 {
+				return
+			}
+
+			if err = parseValue(v, arr[idx]); err != nil {
+				d.setError(namespace, d.fieldError(namespace, ErrInvalidValue, arr[idx], typ, err))
+				return
+			}
+
+			set = true
+			return
+		}
+
 		d.parseMapData()
 		// must be recursing infinitly...but that's ok we caught it on the very first overun
 		if len(namespace) > d.maxKeyLen {
@@ -637,6 +1949,10 @@ func (d *decoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 func (d *decoder) traverseStruct(v reflect.Value, typ reflect.Type, namespace []byte) (set bool) {
 	l := len(namespace)
 	first := l == 0
+	// embedded structs have their hooks called through the struct embedding them
+	hooks := !d.embedded
+	d.embedded = false
+
 	// anonymous structs will still work for caching,
 	// since the entire definition is stored,
 	// including tags
@@ -645,26 +1961,79 @@ func (d *decoder) traverseStruct(v reflect.Value, typ reflect.Type, namespace []
 		s = d.d.structCache.parseStruct(d.d.mode, v, typ, d.d.tagName)
 	}
 
-	for _, f := range s.fields {
+	if hooks {
+		d.beforeDecode(v, s.methods, namespace)
+	}
+
+	// fields of embedded structs share the keys of the struct embedding them
+	shared := d.d.embedAnonymous && !d.d.separateAnonymous && (!hooks || len(s.fields) > 0 && s.fields[len(s.fields)-1].isAnonymous)
+	pl := len(d.path)
+	field, structType := d.field, d.structType
+	d.structType = typ
+	for i := range s.fields {
+		f := &s.fields[i]
+		d.field = f
 		namespace = namespace[:l]
-		if f.isAnonymous {
+		d.path = d.path[:pl]
+		if pl > 0 {
+			d.path = append(d.path, '.')
+		}
+
+		d.path = append(d.path, f.fieldName...)
+		if f.isAnonymous && d.d.embedAnonymous {
+			d.embedded = true
 			if d.setFieldByType(v.Field(f.idx), namespace, 0) {
 				set = true
 			}
+
+			d.embedded = false
+			if !d.d.separateAnonymous {
+				continue
+			}
+		}
+
+		name := f.name
+		if d.d.escapeKeys {
+			name = escapeKey(name)
 		}
 
 		if first {
-			namespace = append(namespace, f.name...)
+			namespace = append(namespace, name...)
 		} else {
 			namespace = append(namespace, d.d.namespacePrefix...)
-			namespace = append(namespace, f.name...)
+			namespace = append(namespace, name...)
 			namespace = append(namespace, d.d.namespaceSuffix...)
 		}
 
-		if d.setFieldByType(v.Field(f.idx), namespace, 0) {
+		idx := 0
+		if shared {
+			var ok bool
+			if idx, ok = d.sharedIndex(v.Field(f.idx).Type(), namespace); !ok {
+				continue
+			}
+		}
+
+		if d.setFieldByType(v.Field(f.idx), namespace, idx) {
 			set = true
+			if f.constraints != nil {
+				d.checkConstraints(v.Field(f.idx), namespace, f.constraints)
+			}
+		} else if (f.defaultValues != nil || f.isRequired) && !d.present(namespace) {
+			switch {
+			case f.defaultValues != nil:
+				d.setValues(v.Field(f.idx), namespace, f.defaultValues)
+			case first || d.present(namespace[:l]):
+				// a required field is only missing when the struct it belongs to has values
+				d.setError(namespace, d.fieldError(namespace, ErrRequired, "", nil, nil))
+			}
 		}
 	}
 
+	d.path = d.path[:pl]
+	d.field, d.structType = field, structType
+	if hooks && (set || first) {
+		d.afterDecode(v, s.methods, namespace[:l])
+	}
+
 	return
 }
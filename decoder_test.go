@@ -1,10 +1,19 @@
 package form
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
 	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -841,7 +850,7 @@ func TestDecoderErrors(t *testing.T) {
 		MapBadFloat32Key      map[float32]float32
 		MapBadFloat64Key      map[float64]float64
 		MapBadBoolKey         map[bool]bool
-		MapBadKeyType         map[complex64]int
+		MapBadKeyType         map[[2]int]int
 		BadArrayValue         []int
 		BadMapKey             map[time.Time]string
 		OverflowNilArray      []int
@@ -952,7 +961,11 @@ func TestDecoderErrors(t *testing.T) {
 			assert.Equal(t, k.Error(), "Bad Type Conversion")
 
 			k = err["Time"]
-			assert.Equal(t, k.Error(), "parsing time \"bad\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"bad\" as \"2006\"")
+			assert.Equal(t, k.Error(), "Invalid Time Value 'bad' Type 'time.Time' Namespace 'Time'")
+			assert.Equal(t, errors.Is(k, ErrInvalidTime), true)
+
+			var timeErr *time.ParseError
+			assert.Equal(t, errors.As(k, &timeErr), true)
 
 			k = err["MapBadIntKey"]
 			assert.Equal(t, k.Error(), "Invalid Integer Value 'key' Type 'int' Namespace 'MapBadIntKey'")
@@ -988,7 +1001,7 @@ func TestDecoderErrors(t *testing.T) {
 			assert.Equal(t, k.Error(), "Invalid Boolean Value 'uh-huh' Type 'bool' Namespace 'MapBadBoolKey'")
 
 			k = err["MapBadKeyType"]
-			assert.Equal(t, k.Error(), "Unsupported Map Key '1.4', Type 'complex64' Namespace 'MapBadKeyType'")
+			assert.Equal(t, k.Error(), "Unsupported Map Key Value '1.4' Type '[2]int' Namespace 'MapBadKeyType'")
 
 			k = err["BadArrayValue[0]"]
 			assert.Equal(t, k.Error(), "Invalid Integer Value 'badintval' Type 'int' Namespace 'BadArrayValue[0]'")
@@ -1023,7 +1036,7 @@ func TestDecoderErrors(t *testing.T) {
 			assert.NotEqual(t, e, "")
 
 			k = err["BadMapKey"]
-			assert.Equal(t, k.Error(), "Unsupported Map Key 'badtime', Type 'time.Time' Namespace 'BadMapKey'")
+			assert.Equal(t, k.Error(), "Unsupported Map Key Value 'badtime' Type 'time.Time' Namespace 'BadMapKey'")
 		})
 	}
 }
@@ -1229,10 +1242,13 @@ func TestDecoderPanicsAndBadValues(t *testing.T) {
 	var test TestError
 	decoder := NewDecoder()
 
-	assert.PanicMatches(t, func() { _ = decoder.Decode(&test, values) }, "Invalid formatting for key 'Phone[0.Number' missing ']' bracket")
+	err := decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Phone[0.Number ERROR:Invalid formatting for key 'Phone[0.Number' missing ']' bracket")
+	assert.Equal(t, errors.Is(err.(DecodeErrors)["Phone[0.Number"], ErrMissingEndBracket), true)
 
 	i := 1
-	err := decoder.Decode(i, values)
+	err = decoder.Decode(i, values)
 	assert.NotEqual(t, err, nil)
 
 	_, ok := err.(*InvalidDecoderError)
@@ -1258,19 +1274,138 @@ func TestDecoderPanicsAndBadValues(t *testing.T) {
 		"Phone0].Number": []string{"1(111)111-1111"},
 	}
 
-	assert.PanicMatches(t, func() { _ = decoder.Decode(&test, values) }, "Invalid formatting for key 'Phone0].Number' missing '[' bracket")
+	err = decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Phone0].Number ERROR:Invalid formatting for key 'Phone0].Number' missing '[' bracket")
+	assert.Equal(t, errors.Is(err.(DecodeErrors)["Phone0].Number"], ErrMissingStartBracket), true)
 
 	values = url.Values{
 		"Phone[[0.Number": []string{"1(111)111-1111"},
 	}
 
-	assert.PanicMatches(t, func() { _ = decoder.Decode(&test, values) }, "Invalid formatting for key 'Phone[[0.Number' missing ']' bracket")
+	err = decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Phone[[0.Number ERROR:Invalid formatting for key 'Phone[[0.Number' missing ']' bracket")
 
 	values = url.Values{
 		"Phone0]].Number": []string{"1(111)111-1111"},
 	}
 
-	assert.PanicMatches(t, func() { _ = decoder.Decode(&test, values) }, "Invalid formatting for key 'Phone0]].Number' missing '[' bracket")
+	err = decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Phone0]].Number ERROR:Invalid formatting for key 'Phone0]].Number' missing '[' bracket")
+
+	// valid keys are still decoded alongside malformed ones
+	values = url.Values{
+		"Phone[0].Number":   []string{"1(111)111-1111"},
+		"Phone2[0.Number":   []string{"2(222)222-2222"},
+		"Phone3[0]].Number": []string{"3(333)333-3333"},
+	}
+
+	test = TestError{}
+	err = decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, len(err.(DecodeErrors)), 2)
+	assert.Equal(t, len(test.Phone), 1)
+	assert.Equal(t, test.Phone[0].Number, "1(111)111-1111")
+	assert.Equal(t, len(test.Phone2), 0)
+	assert.Equal(t, len(test.Phone3), 0)
+}
+
+func TestDecoderInvalidIndexes(t *testing.T) {
+	type Test struct {
+		Slice    []int
+		Array    [2]int
+		Overflow []int
+		Nested   []struct{ Value int }
+		IntMap   map[int]int
+	}
+
+	values := url.Values{
+		"Slice[-1]":                         []string{"1"},
+		"Array[-2]":                         []string{"1"},
+		"Overflow[99999999999999999999]":    []string{"1"},
+		"Nested[9223372036854775807].Value": []string{"1"},
+		"IntMap[-1]":                        []string{"1"},
+	}
+
+	var test Test
+	decoder := NewDecoder()
+	err := decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 4)
+	assert.Equal(t, errs["Slice"].Error(), "Invalid formatting for key 'Slice[-1]' negative index")
+	assert.Equal(t, errors.Is(errs["Slice"], ErrNegativeIndex), true)
+	assert.Equal(t, errs["Array"].Error(), "Invalid formatting for key 'Array[-2]' negative index")
+	assert.Equal(t, errors.Is(errs["Array"], ErrNegativeIndex), true)
+	assert.Equal(t, errs["Overflow"].Error(), "Invalid formatting for key 'Overflow[99999999999999999999]' index overflows int")
+	assert.Equal(t, errors.Is(errs["Overflow"], ErrIndexOverflow), true)
+	assert.Equal(t, errs["Nested"].Error(), "Array size of '9223372036854775808' is larger than the maximum currently set on the decoder of '10000'. To increase this limit please see, SetMaxArraySize(size uint)")
+
+	var keyErr *KeyError
+	assert.Equal(t, errors.As(errs["Overflow"], &keyErr), true)
+	assert.Equal(t, keyErr.Key, "Overflow[99999999999999999999]")
+	assert.Equal(t, test.IntMap[-1], 1)
+}
+
+func FuzzDecode(f *testing.F) {
+	type Nested struct {
+		Name  string
+		Value *int
+	}
+
+	type Test struct {
+		String    string
+		Int       int
+		Uint8     uint8
+		Float     float64
+		Bool      bool
+		Time      time.Time
+		Iface     interface{}
+		Slice     []int
+		Array     [3]string
+		Nested    Nested
+		NestedPtr *Nested
+		Structs   []Nested
+		Map       map[string]int
+		IntMap    map[int][]string
+		MapMap    map[string]map[int]Nested
+		Matrix    [][2]int
+	}
+
+	seeds := []string{
+		"String=a&Int=1&Uint8=2&Float=1.5&Bool=true&Time=2016-01-02T15:04:05Z",
+		"Slice=1&Slice=2&Slice[3]=4&Array[1]=b&Array=a&Array=b&Array=c&Array=d",
+		"Nested.Name=a&NestedPtr.Value=2&Structs[1].Name=b&Structs[0].Value=1",
+		"Map[a]=1&IntMap[1][0]=a&MapMap[a][1].Name=b&Matrix[0][1]=2",
+		"Slice[-1]=1&Slice[99999999999999999999]=1&Slice[9223372036854775807]=1",
+		"Nested[=a&Nested]=b&Map[[a]]=1&Map[a=1&Time&Slice[]=1&Iface[x]=1",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	decoder := NewDecoder()
+	decoder.SetMaxArraySize(100)
+
+	f.Fuzz(func(t *testing.T, query string) {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+
+		var test Test
+		_ = decoder.Decode(&test, values)
+
+		var m map[string]interface{}
+		_ = decoder.Decode(&m, values)
+
+		var s []map[string]string
+		_ = decoder.Decode(&s, values)
+	})
 }
 
 func TestDecoderMapKeys(t *testing.T) {
@@ -1633,6 +1768,79 @@ func TestDecoderEmbedModes(t *testing.T) {
 	assert.Equal(t, err, nil)
 	assert.Equal(t, b.Field, "B Val")
 	assert.Equal(t, b.A.Field, "A Val")
+
+	values = url.Values{
+		"Field":   []string{"B Val", "A Val"},
+		"A.Field": []string{"A Sep"},
+	}
+	decoder.SetAnonymousMode(AnonymousEmbed)
+	b = B{}
+	err = decoder.Decode(&b, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, b.Field, "B Val")
+	assert.Equal(t, b.A.Field, "A Val")
+
+	decoder.SetAnonymousMode(AnonymousSeparate)
+	b = B{}
+	err = decoder.Decode(&b, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, b.Field, "B Val")
+	assert.Equal(t, b.A.Field, "A Sep")
+
+	type D struct {
+		Field int
+		Tags  []string
+	}
+
+	type C struct {
+		B
+		*D
+		Other string
+	}
+
+	in := C{
+		B:     B{A: A{Field: "A Val"}, Field: "B Val"},
+		D:     &D{Field: 3, Tags: []string{"d"}},
+		Other: "C Val",
+	}
+	encoder := NewEncoder()
+	decoder.SetStrict(true)
+	for _, mode := range []AnonymousMode{AnonymousEmbed, AnonymousSeparate} {
+		encoder.SetAnonymousMode(mode)
+		decoder.SetAnonymousMode(mode)
+		encoded, err := encoder.Encode(in)
+		assert.Equal(t, err, nil)
+
+		var c C
+		err = decoder.Decode(&c, encoded)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, c, in)
+	}
+
+	// the values of a slice shared with an embedded struct can not be told apart, they are decoded into the first
+	type E struct {
+		D
+		Tags []string
+	}
+
+	encoder.SetAnonymousMode(AnonymousEmbed)
+	decoder.SetAnonymousMode(AnonymousEmbed)
+	encoded, err := encoder.Encode(E{D: D{Tags: []string{"d"}}, Tags: []string{"e"}})
+	assert.Equal(t, err, nil)
+
+	var e E
+	err = decoder.Decode(&e, encoded)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, e.Tags, []string{"e", "d"})
+	assert.Equal(t, len(e.D.Tags), 0)
+
+	decoder.SetAnonymousMode(AnonymousSeparate)
+
+	// flattened keys are left unused in AnonymousSeparate mode
+	var c C
+	err = decoder.Decode(&c, url.Values{"Field": []string{"B Val"}})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, c.B.Field, "")
 }
 
 func TestInterfaceDecoding(t *testing.T) {
@@ -1780,10 +1988,10 @@ func TestDecoder_InvalidSliceIndex(t *testing.T) {
 	v := new(PostsRequest)
 	d := NewDecoder()
 	err := d.Decode(v, in)
-	assert.NotEqual(t, err, nil)
-	assert.Equal(t, err.Error(), "Field Namespace:PostIds ERROR:invalid slice index ''")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, v.PostIds, []string{"1", "2"})
 
-	// No error with proper name
+	// No error with the brackets in the name
 	type PostsRequest2 struct {
 		PostIds []string `form:"PostIds[]"`
 	}
@@ -1795,3 +2003,1859 @@ func TestDecoder_InvalidSliceIndex(t *testing.T) {
 	assert.Equal(t, v2.PostIds[0], "1")
 	assert.Equal(t, v2.PostIds[1], "2")
 }
+
+func TestDecoderStrict(t *testing.T) {
+	type Address struct {
+		Name  string
+		Phone string
+	}
+
+	type User struct {
+		Name    string
+		Age     int
+		Tags    []string
+		Address []Address
+		Map     map[string]int
+		Time    time.Time
+		Nested  Address
+	}
+
+	values := url.Values{
+		"Name":             []string{"joeybloggs"},
+		"Age":              []string{"bad"},
+		"Tags":             []string{"a", "b"},
+		"Address[0].Name":  []string{"26 Here Blvd."},
+		"Adress[0].Name":   []string{"typo"},
+		"Address[0].Phon":  []string{"typo"},
+		"Map[key]":         []string{"1"},
+		"Time":             []string{"2016-10-01T15:04:05Z"},
+		"Nested":           []string{"no custom type"},
+		"Nested.Phone":     []string{"1(111)111-1111"},
+		"Unknown":          []string{"value"},
+		"Address[0][Name]": []string{"wrong syntax"},
+	}
+
+	var user User
+	decoder := NewDecoder()
+	err := decoder.Decode(&user, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, len(err.(DecodeErrors)), 1)
+
+	decoder.SetStrict(true)
+	user = User{}
+	err = decoder.Decode(&user, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 6)
+	assert.Equal(t, errs["Age"].Error(), "Invalid Integer Value 'bad' Type 'int' Namespace 'Age'")
+	assert.Equal(t, errs["Adress[0].Name"].Error(), "Unknown Key Namespace 'Adress[0].Name'")
+	assert.Equal(t, errs["Address[0].Phon"].Error(), "Unknown Key Namespace 'Address[0].Phon'")
+	assert.Equal(t, errs["Nested"].Error(), "Unknown Key Namespace 'Nested'")
+	assert.Equal(t, errs["Unknown"].Error(), "Unknown Key Namespace 'Unknown'")
+	assert.Equal(t, errs["Address[0][Name]"].Error(), "Unknown Key Namespace 'Address[0][Name]'")
+	assert.Equal(t, user.Name, "joeybloggs")
+	assert.Equal(t, user.Tags, []string{"a", "b"})
+	assert.Equal(t, user.Address[0].Name, "26 Here Blvd.")
+	assert.Equal(t, user.Map["key"], 1)
+	assert.Equal(t, user.Nested.Phone, "1(111)111-1111")
+
+	// values of a slice of structs are not decoded into its elements
+	user = User{}
+	err = decoder.Decode(&user, url.Values{"Name": []string{"joeybloggs"}, "Address": []string{"q"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errors.Is(errs["Address"], ErrUnknownKey), true)
+
+	// all keys used, no errors; the decoder can be reused
+	user = User{}
+	err = decoder.Decode(&user, url.Values{"Name": []string{"joeybloggs"}, "Nested.Name": []string{"name"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Nested.Name, "name")
+
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return Address{Name: vals[0]}, nil
+	}, Address{})
+
+	user = User{}
+	err = decoder.Decode(&user, url.Values{"Nested": []string{"custom"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Nested.Name, "custom")
+}
+
+func TestDecoderArrayOverflowWarnings(t *testing.T) {
+	var data struct {
+		A [2]string
+		B [2]string
+		C [3]int
+	}
+
+	values := url.Values{
+		"A":    {"10", "20", "30", "40"},
+		"B[1]": {"20"},
+		"B[5]": {"50"},
+		"C[1]": {"1"},
+	}
+
+	var warnings []DecodeWarning
+	decoder := NewDecoder()
+	decoder.RegisterWarningFunc(func(w DecodeWarning) {
+		warnings = append(warnings, w)
+	})
+
+	err := decoder.Decode(&data, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, data.A, [2]string{"10", "20"})
+	assert.Equal(t, data.B, [2]string{"", "20"})
+	assert.Equal(t, data.C, [3]int{0, 1, 0})
+	assert.Equal(t, len(warnings), 2)
+
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Namespace < warnings[j].Namespace })
+	assert.Equal(t, warnings[0], DecodeWarning{Namespace: "A", Kind: WarningArrayOverflow, Values: []string{"30", "40"}})
+	assert.Equal(t, warnings[1], DecodeWarning{Namespace: "B[5]", Kind: WarningArrayOverflow, Values: []string{"50"}})
+	assert.Equal(t, warnings[0].Kind.String(), "array overflow")
+
+	warnings = nil
+	decoder.SetArrayOverflowError(true)
+	err = decoder.Decode(&data, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, len(warnings), 0)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs["A"].Error(), "Array Overflow, values exceed array length '2' Namespace 'A'")
+	assert.Equal(t, errs["B[5]"].Error(), "Array Overflow, values exceed array length '2' Namespace 'B[5]'")
+	assert.Equal(t, errors.Is(errs["A"], ErrArrayOverflow), true)
+	assert.Equal(t, errs["B[5]"].(*FieldError).Param, "2")
+	assert.Equal(t, errs["B[5]"].(*FieldError).Field, "B[5]")
+
+	// the values of an element beyond the length of an array of structs
+	type Item struct {
+		Name string
+		Zip  int
+	}
+
+	var items struct {
+		Items [1]Item
+	}
+
+	warnings = nil
+	decoder.SetArrayOverflowError(false)
+	err = decoder.Decode(&items, url.Values{"Items[0].Name": {"a"}, "Items[3].Name": {"x"}, "Items[3].Zip": {"1"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, items.Items, [1]Item{{Name: "a"}})
+	assert.Equal(t, warnings, []DecodeWarning{{Namespace: "Items[3]", Kind: WarningArrayOverflow, Values: []string{"x", "1"}}})
+}
+
+func TestDecoderLimits(t *testing.T) {
+	type Test struct {
+		Name   string
+		Tags   []string
+		Map    map[string]int
+		Nested struct {
+			Map map[string]map[string]string
+		}
+	}
+
+	tests := []struct {
+		limits Limits
+		values url.Values
+		key    string
+		err    error
+		msg    string
+	}{
+		{
+			limits: Limits{MaxKeys: 1},
+			values: url.Values{"Name": {"a"}, "Tags": {"b"}},
+			err:    ErrTooManyKeys,
+			msg:    "Limit Exceeded, too many keys limit '1'",
+		},
+		{
+			limits: Limits{MaxValuesPerKey: 2},
+			values: url.Values{"Name": {"a"}, "Tags": {"a", "b", "c"}},
+			key:    "Tags",
+			err:    ErrTooManyValues,
+			msg:    "Limit Exceeded, too many values limit '2' Key 'Tags'",
+		},
+		{
+			limits: Limits{MaxKeyLength: 4},
+			values: url.Values{"Name": {"a"}, "Map[key]": {"1"}},
+			key:    "Map[key]",
+			err:    ErrKeyTooLong,
+			msg:    "Limit Exceeded, key too long limit '4' Key 'Map[key]'",
+		},
+		{
+			limits: Limits{MaxDepth: 3},
+			values: url.Values{"Map[a]": {"1"}, "Nested.Map[a][b]": {"c"}},
+			key:    "Nested.Map[a][b]",
+			err:    ErrMaxDepth,
+			msg:    "Limit Exceeded, maximum depth exceeded limit '3' Key 'Nested.Map[a][b]'",
+		},
+	}
+
+	for _, tt := range tests {
+		var test Test
+		decoder := NewDecoder()
+		decoder.SetLimits(tt.limits)
+		err := decoder.Decode(&test, tt.values)
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, errors.Is(err, tt.err), true)
+		assert.Equal(t, err.Error(), tt.msg)
+		assert.Equal(t, err.(*LimitError).Key, tt.key)
+		assert.Equal(t, test.Name, "")
+	}
+
+	var test Test
+	decoder := NewDecoder()
+	decoder.SetLimits(Limits{MaxMapEntries: 2})
+	err := decoder.Decode(&test, url.Values{"Map[a]": {"1"}, "Map[b]": {"2"}, "Map[c]": {"3"}})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, errors.Is(err.(DecodeErrors)["Map"], ErrTooManyMapEntries), true)
+	assert.Equal(t, len(test.Map), 2)
+
+	test = Test{}
+	decoder.SetLimits(Limits{MaxElements: 3})
+	err = decoder.Decode(&test, url.Values{"Map[a]": {"1"}, "Map[b]": {"1"}, "Map[c]": {"1"}, "Map[d]": {"1"}})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, errors.Is(err.(DecodeErrors)["Map"], ErrTooManyElements), true)
+	assert.Equal(t, len(test.Map), 3)
+
+	test = Test{}
+	err = decoder.Decode(&test, url.Values{"Tags[1000]": {"a"}})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.(DecodeErrors)["Tags"].Error(), "Limit Exceeded, too many elements limit '3' Key 'Tags'")
+	assert.Equal(t, len(test.Tags), 0)
+
+	// limits are not exceeded
+	test = Test{}
+	decoder.SetLimits(Limits{MaxKeys: 3, MaxValuesPerKey: 2, MaxKeyLength: 16, MaxDepth: 4, MaxMapEntries: 2, MaxElements: 5})
+	err = decoder.Decode(&test, url.Values{"Tags": {"a", "b"}, "Map[a]": {"1"}, "Nested.Map[a][b]": {"c"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Tags, []string{"a", "b"})
+	assert.Equal(t, test.Nested.Map["a"]["b"], "c")
+
+	// depth counts the configured namespace prefix
+	decoder.SetNamespacePrefix("[")
+	decoder.SetNamespaceSuffix("]")
+	decoder.SetLimits(Limits{MaxDepth: 3})
+	err = decoder.Decode(&test, url.Values{"Nested[Map][a][b]": {"c"}})
+	assert.Equal(t, errors.Is(err, ErrMaxDepth), true)
+}
+
+func TestDecoderFieldErrors(t *testing.T) {
+	type Phone struct {
+		Number int `form:"number"`
+	}
+
+	type Address struct {
+		Phones []Phone `form:"phones"`
+	}
+
+	type Test struct {
+		Active    bool               `form:"active"`
+		Addresses []Address          `form:"address"`
+		Counts    map[string]uint8   `form:"counts"`
+		Ratios    []float32          `form:"ratios"`
+		Keys      map[int]string     `form:"keys"`
+		Nested    map[string][]Phone `form:"nested"`
+	}
+
+	values := url.Values{
+		"active":                      []string{"maybe"},
+		"address[1].phones[0].number": []string{"12a"},
+		"counts[a]":                   []string{"256"},
+		"ratios":                      []string{"1.5", "x"},
+		"keys[one]":                   []string{"1"},
+		"nested[a][2].number":         []string{"-"},
+	}
+
+	var test Test
+	decoder := NewDecoder()
+	err := decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, errors.Is(err, ErrInvalidBool), true)
+	assert.Equal(t, errors.Is(err, ErrInvalidInteger), true)
+	assert.Equal(t, errors.Is(err, ErrInvalidUnsignedInteger), true)
+	assert.Equal(t, errors.Is(err, ErrInvalidFloat), true)
+	assert.Equal(t, errors.Is(err, ErrUnsupportedMapKey), false)
+	assert.Equal(t, errors.Is(err, strconv.ErrRange), true)
+
+	expected := `Field Namespace:active ERROR:Invalid Boolean Value 'maybe' Type 'bool' Namespace 'active'
+Field Namespace:address[1].phones[0].number ERROR:Invalid Integer Value '12a' Type 'int' Namespace 'address[1].phones[0].number'
+Field Namespace:counts[a] ERROR:Invalid Unsigned Integer Value '256' Type 'uint8' Namespace 'counts[a]'
+Field Namespace:keys ERROR:Invalid Integer Value 'one' Type 'int' Namespace 'keys'
+Field Namespace:nested[a][2].number ERROR:Invalid Integer Value '-' Type 'int' Namespace 'nested[a][2].number'
+Field Namespace:ratios ERROR:Invalid Float Value 'x' Type 'float32' Namespace 'ratios'`
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, err.Error(), expected)
+	}
+
+	errs := err.(DecodeErrors)
+	tests := []struct {
+		ns    string
+		field string
+		value string
+		typ   reflect.Type
+		kind  error
+	}{
+		{ns: "active", field: "Active", value: "maybe", typ: reflect.TypeOf(true), kind: ErrInvalidBool},
+		{ns: "address[1].phones[0].number", field: "Addresses[1].Phones[0].Number", value: "12a", typ: reflect.TypeOf(0), kind: ErrInvalidInteger},
+		{ns: "counts[a]", field: "Counts[a]", value: "256", typ: reflect.TypeOf(uint8(0)), kind: ErrInvalidUnsignedInteger},
+		{ns: "ratios", field: "Ratios[1]", value: "x", typ: reflect.TypeOf(float32(0)), kind: ErrInvalidFloat},
+		{ns: "keys", field: "Keys", value: "one", typ: reflect.TypeOf(0), kind: ErrInvalidInteger},
+		{ns: "nested[a][2].number", field: "Nested[a][2].Number", value: "-", typ: reflect.TypeOf(0), kind: ErrInvalidInteger},
+	}
+
+	for _, tt := range tests {
+		var fe *FieldError
+		assert.Equal(t, errors.As(errs[tt.ns], &fe), true)
+		assert.Equal(t, fe.Namespace, tt.ns)
+		assert.Equal(t, fe.Field, tt.field)
+		assert.Equal(t, fe.Value, tt.value)
+		assert.Equal(t, fe.Type == tt.typ, true)
+		assert.Equal(t, fe.Kind, tt.kind)
+
+		var numErr *strconv.NumError
+		assert.Equal(t, errors.As(fe, &numErr), true)
+		assert.Equal(t, numErr.Num, tt.value)
+	}
+
+	// custom type function errors keep their message and are wrapped
+	customErr := errors.New("custom failure")
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return nil, customErr
+	}, uint8(0))
+
+	test = Test{}
+	err = decoder.Decode(&test, url.Values{"counts[a]": []string{"1"}})
+	assert.Equal(t, err.Error(), "Field Namespace:counts[a] ERROR:custom failure")
+	assert.Equal(t, errors.Is(err, customErr), true)
+
+	var fe *FieldError
+	assert.Equal(t, errors.As(err, &fe), true)
+	assert.Equal(t, fe.Field, "Counts[a]")
+	assert.Equal(t, fe.Kind, nil)
+}
+
+type textID int
+
+func (id *textID) UnmarshalText(text []byte) error {
+	if len(text) < 3 || string(text[:3]) != "id-" {
+		return errors.New("missing id- prefix")
+	}
+
+	i, err := strconv.Atoi(string(text[3:]))
+	*id = textID(i)
+	return err
+}
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte("id-" + strconv.Itoa(int(id))), nil
+}
+
+func TestDecoderTextUnmarshaler(t *testing.T) {
+	type Test struct {
+		Addr    netip.Addr
+		AddrPtr *netip.Addr
+		Prefix  netip.Prefix
+		Addrs   []netip.Addr
+		IP      net.IP
+		ID      textID
+		IDPtr   *textID
+		IDs     []textID
+		IDMap   map[textID]textID
+		AddrMap map[netip.Addr]string
+		Big     *big.Int
+		Empty   netip.Addr
+		Bad     textID
+		BadKey  map[textID]string
+		Custom  textID
+	}
+
+	values := url.Values{
+		"Addr":               []string{"127.0.0.1"},
+		"AddrPtr":            []string{"::1"},
+		"Prefix":             []string{"10.0.0.0/8"},
+		"Addrs":              []string{"10.0.0.1", "10.0.0.2"},
+		"Addrs[3]":           []string{"10.0.0.4"},
+		"IP":                 []string{"192.168.0.1"},
+		"ID":                 []string{"id-1"},
+		"IDPtr":              []string{"id-2"},
+		"IDs":                []string{"id-3", "id-4"},
+		"IDMap[id-5]":        []string{"id-6"},
+		"AddrMap[127.0.0.1]": []string{"localhost"},
+		"Big":                []string{"123456789012345678901234567890"},
+		"Empty":              []string{""},
+		"Bad":                []string{"1"},
+		"BadKey[1]":          []string{"1"},
+		"Custom":             []string{"7"},
+	}
+
+	var test Test
+	decoder := NewDecoder()
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		i, err := strconv.Atoi(vals[0])
+		return textID(i * 10), err
+	}, textID(0))
+
+	err := decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, test.Custom, textID(70))
+	assert.Equal(t, test.ID, textID(0))
+	assert.Equal(t, len(err.(DecodeErrors)), 4)
+	assert.Equal(t, test.Addr, netip.MustParseAddr("127.0.0.1"))
+
+	// custom type funcs take priority, remove it to test the failure cases
+	decoder = NewDecoder()
+	test = Test{}
+	err = decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 3)
+	assert.Equal(t, errs["Bad"].Error(), "Invalid Value '1' Type 'form.textID' Namespace 'Bad'")
+	assert.Equal(t, errors.Is(errs["Bad"], ErrInvalidValue), true)
+	assert.Equal(t, errs["BadKey"].Error(), "Invalid Value '1' Type 'form.textID' Namespace 'BadKey'")
+	assert.Equal(t, errs["Custom"].Error(), "Invalid Value '7' Type 'form.textID' Namespace 'Custom'")
+
+	assert.Equal(t, test.Addr, netip.MustParseAddr("127.0.0.1"))
+	assert.Equal(t, *test.AddrPtr, netip.MustParseAddr("::1"))
+	assert.Equal(t, test.Prefix, netip.MustParsePrefix("10.0.0.0/8"))
+	assert.Equal(t, test.Addrs, []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2"), {}, netip.MustParseAddr("10.0.0.4")})
+	assert.Equal(t, test.IP.String(), "192.168.0.1")
+	assert.Equal(t, test.ID, textID(1))
+	assert.Equal(t, *test.IDPtr, textID(2))
+	assert.Equal(t, test.IDs, []textID{3, 4})
+	assert.Equal(t, test.IDMap[5], textID(6))
+	assert.Equal(t, test.AddrMap[netip.MustParseAddr("127.0.0.1")], "localhost")
+	assert.Equal(t, test.Big.String(), "123456789012345678901234567890")
+	assert.Equal(t, test.Empty.IsValid(), false)
+}
+
+type money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m *money) UnmarshalForm(values url.Values) error {
+	if v := values.Get(""); v != "" {
+		_, err := fmt.Sscanf(v, "%d %s", &m.Amount, &m.Currency)
+		return err
+	}
+
+	amount, err := strconv.ParseInt(values.Get("Amount"), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	m.Amount = amount
+	m.Currency = values.Get("Currency")
+	if m.Currency == "" {
+		return errors.New("missing currency")
+	}
+
+	return nil
+}
+
+func (m money) MarshalForm() (url.Values, error) {
+	if m.Currency == "" {
+		return nil, errors.New("missing currency")
+	}
+
+	return url.Values{
+		"Amount":   []string{strconv.FormatInt(m.Amount, 10)},
+		"Currency": []string{m.Currency},
+	}, nil
+}
+
+func TestDecoderUnmarshaler(t *testing.T) {
+	type Test struct {
+		Price     money
+		PriceList []string
+		Total     *money
+		Missing   *money
+		Prices    []money
+		Bad       money
+		ByKey     map[string]money
+		Combined  money
+	}
+
+	values := url.Values{
+		"Price.Amount":       []string{"100"},
+		"Price.Currency":     []string{"EUR"},
+		"PriceList":          []string{"a"},
+		"Total.Amount":       []string{"200"},
+		"Total.Currency":     []string{"USD"},
+		"Prices[1].Amount":   []string{"1"},
+		"Prices[1].Currency": []string{"GBP"},
+		"Bad.Amount":         []string{"1"},
+		"ByKey[a].Amount":    []string{"2"},
+		"ByKey[a].Currency":  []string{"JPY"},
+		"Combined":           []string{"5 CHF"},
+	}
+
+	var test Test
+	decoder := NewDecoder()
+	decoder.SetStrict(true)
+	err := decoder.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Bad"].Error(), "missing currency")
+	assert.Equal(t, test.Price, money{Amount: 100, Currency: "EUR"})
+	assert.Equal(t, test.PriceList, []string{"a"})
+	assert.Equal(t, *test.Total, money{Amount: 200, Currency: "USD"})
+	assert.Equal(t, test.Missing, nil)
+	assert.Equal(t, test.Prices, []money{{}, {Amount: 1, Currency: "GBP"}})
+	assert.Equal(t, test.ByKey["a"], money{Amount: 2, Currency: "JPY"})
+	assert.Equal(t, test.Combined, money{Amount: 5, Currency: "CHF"})
+
+	// bracket namespaces and the root value
+	decoder = NewDecoder()
+	decoder.SetNamespacePrefix("[")
+	decoder.SetNamespaceSuffix("]")
+
+	test = Test{}
+	err = decoder.Decode(&test, url.Values{"Price[Amount]": []string{"3"}, "Price[Currency]": []string{"SEK"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Price, money{Amount: 3, Currency: "SEK"})
+
+	var m money
+	err = decoder.Decode(&m, url.Values{"Amount": []string{"4"}, "Currency": []string{"NOK"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, m, money{Amount: 4, Currency: "NOK"})
+}
+
+func TestDecoderTyped(t *testing.T) {
+	type customString string
+
+	type Test struct {
+		Name  customString
+		Slice []customString
+		Map   map[customString]customString
+		Ptr   *customString
+		Bad   customString
+	}
+
+	values := url.Values{
+		"Name":       []string{"joeybloggs"},
+		"Slice":      []string{"v1", "v2"},
+		"Map[key]":   []string{"value"},
+		"Ptr":        []string{"ptr"},
+		"Bad":        []string{"bad"},
+		"Unassigned": []string{"1"},
+	}
+
+	d := NewDecoder()
+	RegisterDecoder(d, func(s string) (customString, error) {
+		if s == "bad" {
+			return "", errors.New("bad value")
+		}
+		return customString("custom" + s), nil
+	})
+
+	test, err := DecodeAs[Test](d, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Bad"].Error(), "bad value")
+	assert.Equal(t, test.Name, customString("customjoeybloggs"))
+	assert.Equal(t, test.Slice, []customString{"customv1", "customv2"})
+	assert.Equal(t, test.Map, map[customString]customString{"customkey": "customvalue"})
+	assert.Equal(t, *test.Ptr, customString("customptr"))
+
+	m, err := DecodeAs[map[string]int](NewDecoder(), url.Values{"[a]": []string{"1"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, m, map[string]int{"a": 1})
+}
+
+func TestDecoderCustomTypeMismatch(t *testing.T) {
+	type customString string
+
+	type Test struct {
+		Name customString
+		Nil  customString
+	}
+
+	d := NewDecoder()
+	d.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		if vals[0] == "nil" {
+			return nil, nil
+		}
+		return vals[0], nil
+	}, customString(""))
+
+	test := Test{Nil: "set"}
+	err := d.Decode(&test, url.Values{"Name": []string{"joeybloggs"}, "Nil": []string{"nil"}})
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Name"].Error(), "custom type function returned type 'string' which is not assignable to type 'form.customString'")
+	assert.Equal(t, test.Name, customString(""))
+	assert.Equal(t, test.Nil, customString(""))
+}
+
+func TestDecoderDefaults(t *testing.T) {
+	type customString string
+
+	type Filter struct {
+		Status string `form:"status,default=open"`
+	}
+
+	type Test struct {
+		Page     int          `form:"page,default=1"`
+		PerPage  *uint        `form:"per_page,omitempty,default=20"`
+		Sort     string       `form:"sort,default=created_at"`
+		Order    string       `form:"order,default=desc"`
+		Tags     []string     `form:"tags,default=a|b"`
+		Fields   string       `form:"fields,default=id,name"`
+		Since    time.Time    `form:"since,default=2020-01-02T03:04:05Z"`
+		Custom   customString `form:"custom,default=value"`
+		ID       textID       `form:"id,default=id-7"`
+		Filter   Filter       `form:"filter"`
+		FilterP  *Filter      `form:"filterp"`
+		Empty    string       `form:"empty,default=x"`
+		NoTag    int
+		Explicit int `form:",default=3"`
+	}
+
+	values := url.Values{
+		"order":   []string{"asc"},
+		"tags[1]": []string{"z"},
+		"empty":   []string{""},
+	}
+
+	d := NewDecoder()
+	d.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return customString("custom" + vals[0]), nil
+	}, customString(""))
+
+	var test Test
+	err := d.Decode(&test, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Page, 1)
+	assert.Equal(t, *test.PerPage, uint(20))
+	assert.Equal(t, test.Sort, "created_at")
+	assert.Equal(t, test.Order, "asc")
+	assert.Equal(t, test.Tags, []string{"", "z"})
+	assert.Equal(t, test.Fields, "id,name")
+	assert.Equal(t, test.Since, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Equal(t, test.Custom, customString("customvalue"))
+	assert.Equal(t, test.ID, textID(7))
+	assert.Equal(t, test.Filter.Status, "open")
+	assert.Equal(t, test.FilterP, nil)
+	assert.Equal(t, test.Empty, "")
+	assert.Equal(t, test.NoTag, 0)
+	assert.Equal(t, test.Explicit, 3)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"filterp.status": []string{"closed"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Tags, []string{"a", "b"})
+	assert.Equal(t, test.FilterP.Status, "closed")
+
+	type BadInt struct {
+		Page int `form:"page,default=one"`
+	}
+
+	type BadMap struct {
+		Map map[string]string `form:"map,default=a"`
+	}
+
+	type BadStruct struct {
+		Filter Filter `form:"filter,default=a"`
+	}
+
+	var badInt BadInt
+	assert.PanicMatches(t, func() { _ = d.Decode(&badInt, url.Values{}) }, "form: invalid default 'one' for field 'Page': Invalid Integer Value 'one' Type 'int' Namespace ''")
+
+	var badMap BadMap
+	assert.PanicMatches(t, func() { _ = d.Decode(&badMap, url.Values{}) }, "form: invalid default 'a' for field 'Map': defaults are not supported for type 'map[string]string'")
+
+	var badStruct BadStruct
+	assert.PanicMatches(t, func() { _ = d.Decode(&badStruct, url.Values{}) }, "form: invalid default 'a' for field 'Filter': defaults are not supported for type 'form.Filter'")
+
+	// the cache is usable after a panic
+	err = d.Decode(&test, url.Values{})
+	assert.Equal(t, err, nil)
+}
+
+func TestDecoderRequired(t *testing.T) {
+	type Address struct {
+		Street string `form:"street"`
+		Phone  string `form:"phone,required"`
+	}
+
+	type Test struct {
+		Name      string    `form:"name,required"`
+		Age       int       `form:"age,required"`
+		Tags      []string  `form:"tags,required"`
+		Page      int       `form:"page,required,default=1"`
+		Address   Address   `form:"address,required"`
+		Addresses []Address `form:"addresses"`
+		Optional  *Address  `form:"optional"`
+		Other     Address   `form:"other"`
+	}
+
+	values := url.Values{
+		"name":                []string{""},
+		"tags[0]":             []string{"a"},
+		"addresses[0].phone":  []string{"1"},
+		"addresses[1].street": []string{"main"},
+		"other.street":        []string{"side"},
+	}
+
+	var test Test
+	d := NewDecoder()
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 4)
+	assert.Equal(t, errs["age"].Error(), "Missing Required Field Namespace 'age'")
+	assert.Equal(t, errs["address"].Error(), "Missing Required Field Namespace 'address'")
+	assert.Equal(t, errs["addresses[1].phone"].Error(), "Missing Required Field Namespace 'addresses[1].phone'")
+	assert.Equal(t, errs["other.phone"].Error(), "Missing Required Field Namespace 'other.phone'")
+	assert.Equal(t, test.Page, 1)
+	assert.Equal(t, test.Optional, nil)
+
+	var fe *FieldError
+	assert.Equal(t, errors.As(errs["addresses[1].phone"], &fe), true)
+	assert.Equal(t, errors.Is(fe, ErrRequired), true)
+	assert.Equal(t, fe.Field, "Addresses[1].Phone")
+
+	values = url.Values{
+		"name":            []string{"joeybloggs"},
+		"age":             []string{"3"},
+		"tags":            []string{"a"},
+		"address.phone":   []string{"2"},
+		"optional.street": []string{"none"},
+	}
+
+	test = Test{}
+	err = d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["optional.phone"].Error(), "Missing Required Field Namespace 'optional.phone'")
+}
+
+func TestDecoderConstraints(t *testing.T) {
+	type Test struct {
+		Age      int               `form:"age,min=18,max=130"`
+		Score    *float64          `form:"score,min=0.5"`
+		Code     string            `form:"code,len=6"`
+		Name     string            `form:"name,min=2,max=4"`
+		Status   string            `form:"status,oneof=open|closed"`
+		Level    uint              `form:"level,oneof=1|2|3"`
+		Slug     string            `form:"slug,pattern=^[a-z-]{1,8}$"`
+		Tags     []string          `form:"tags,max=2,pattern=^[a-z]+$"`
+		Labels   map[string]string `form:"labels,min=1"`
+		Optional string            `form:"optional,len=2"`
+	}
+
+	values := url.Values{
+		"age":         []string{"17"},
+		"score":       []string{"0.25"},
+		"code":        []string{"12345"},
+		"name":        []string{"ñandú"},
+		"status":      []string{"pending"},
+		"level":       []string{"4"},
+		"slug":        []string{"not a slug"},
+		"tags":        []string{"a", "B"},
+		"labels[a]":   []string{"b"},
+		"ignored.age": []string{"1"},
+	}
+
+	var test Test
+	d := NewDecoder()
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 8)
+	assert.Equal(t, errs["age"].Error(), "Less Than Minimum '18' Value '17' Type 'int' Namespace 'age'")
+	assert.Equal(t, errs["score"].Error(), "Less Than Minimum '0.5' Value '0.25' Type 'float64' Namespace 'score'")
+	assert.Equal(t, errs["code"].Error(), "Invalid Length '6' Value '12345' Type 'string' Namespace 'code'")
+	assert.Equal(t, errs["name"].Error(), "Greater Than Maximum '4' Value 'ñandú' Type 'string' Namespace 'name'")
+	assert.Equal(t, errs["status"].Error(), "Not One Of 'open|closed' Value 'pending' Type 'string' Namespace 'status'")
+	assert.Equal(t, errs["level"].Error(), "Not One Of '1|2|3' Value '4' Type 'uint' Namespace 'level'")
+	assert.Equal(t, errs["slug"].Error(), "Does Not Match Pattern '^[a-z-]{1,8}$' Value 'not a slug' Type 'string' Namespace 'slug'")
+	assert.Equal(t, errs["tags"].Error(), "Does Not Match Pattern '^[a-z]+$' Value 'B' Type '[]string' Namespace 'tags'")
+
+	var fe *FieldError
+	assert.Equal(t, errors.As(errs["age"], &fe), true)
+	assert.Equal(t, errors.Is(fe, ErrMin), true)
+	assert.Equal(t, fe.Param, "18")
+	assert.Equal(t, fe.Field, "Age")
+
+	values = url.Values{
+		"age":       []string{"30"},
+		"score":     []string{"1"},
+		"code":      []string{"123456"},
+		"name":      []string{"ñan"},
+		"status":    []string{"open"},
+		"level":     []string{"3"},
+		"slug":      []string{"a-slug"},
+		"tags":      []string{"a", "b", "c"},
+		"labels[a]": []string{"b"},
+	}
+
+	test = Test{}
+	err = d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["tags"].Error(), "Greater Than Maximum '2' Value '3' Type '[]string' Namespace 'tags'")
+
+	type BadMin struct {
+		Value string `form:"value,min=a"`
+	}
+
+	type BadLen struct {
+		Value int `form:"value,len=1"`
+	}
+
+	type BadPattern struct {
+		Value string `form:"value,pattern=["`
+	}
+
+	type BadOneOf struct {
+		Value time.Time `form:"value,oneof=a|b"`
+	}
+
+	var badMin BadMin
+	assert.PanicMatches(t, func() { _ = d.Decode(&badMin, url.Values{}) }, "form: invalid min 'a' for field 'Value'")
+
+	var badLen BadLen
+	assert.PanicMatches(t, func() { _ = d.Decode(&badLen, url.Values{}) }, "form: len is not supported for field 'Value' of type 'int'")
+
+	var badPattern BadPattern
+	assert.PanicMatches(t, func() { _ = d.Decode(&badPattern, url.Values{}) }, "form: invalid pattern '[' for field 'Value': error parsing regexp: missing closing ]: `[`")
+
+	var badOneOf BadOneOf
+	assert.PanicMatches(t, func() { _ = d.Decode(&badOneOf, url.Values{}) }, "form: oneof is not supported for field 'Value' of type 'time.Time'")
+}
+
+type validatedAddress struct {
+	Street      string `form:"street"`
+	PhoneNumber string `form:"phone"`
+}
+
+func (a validatedAddress) Validate() error {
+	if a.PhoneNumber == "" {
+		return &FieldError{Field: "PhoneNumber", Kind: ErrRequired}
+	}
+	return nil
+}
+
+type validatedBase struct {
+	Tenant string `form:"tenant"`
+}
+
+type validatedUser struct {
+	validatedBase
+	Name      string             `form:"name"`
+	Addresses []validatedAddress `form:"Address"`
+	Primary   *validatedAddress  `form:"primary"`
+	Other     validatedAddress   `form:"other"`
+	Fail      bool               `form:"fail"`
+}
+
+func (u *validatedUser) Validate() error {
+	var errs []error
+	if u.Name == "" {
+		errs = append(errs, &FieldError{Field: "Name", Kind: ErrRequired})
+	}
+
+	if u.Tenant == "" {
+		errs = append(errs, &FieldError{Field: "Tenant", Err: errors.New("tenant is missing")})
+	}
+
+	if len(u.Addresses) > 1 && u.Addresses[0].Street == u.Addresses[1].Street {
+		errs = append(errs, &FieldError{Field: "Addresses[1].Street", Err: errors.New("duplicate street")})
+	}
+
+	if u.Fail {
+		errs = append(errs, errors.New("user is invalid"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func TestDecoderValidator(t *testing.T) {
+	values := url.Values{
+		"Address[0].street": []string{"main"},
+		"Address[0].phone":  []string{"1"},
+		"Address[1].street": []string{"main"},
+		"fail":              []string{"true"},
+	}
+
+	var test validatedUser
+	d := NewDecoder()
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 5)
+	assert.Equal(t, errs["name"].Error(), "Missing Required Field Namespace 'name'")
+	assert.Equal(t, errs["tenant"].Error(), "tenant is missing")
+	assert.Equal(t, errs["Address[1].street"].Error(), "duplicate street")
+	assert.Equal(t, errs["Address[1].phone"].Error(), "Missing Required Field Namespace 'Address[1].phone'")
+	assert.Equal(t, errs[""].Error(), "user is invalid")
+
+	var fe *FieldError
+	assert.Equal(t, errors.As(errs["Address[1].phone"], &fe), true)
+	assert.Equal(t, fe.Field, "Addresses[1].PhoneNumber")
+	assert.Equal(t, errors.As(errs["Address[1].street"], &fe), true)
+	assert.Equal(t, fe.Field, "Addresses[1].Street")
+
+	values = url.Values{
+		"name":          []string{"joeybloggs"},
+		"tenant":        []string{"acme"},
+		"primary.phone": []string{"2"},
+		"other.street":  []string{"side"},
+	}
+
+	test = validatedUser{}
+	err = d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["other.phone"].Error(), "Missing Required Field Namespace 'other.phone'")
+	assert.Equal(t, errors.As(errs["other.phone"], &fe), true)
+	assert.Equal(t, fe.Field, "Other.PhoneNumber")
+
+	// bracket namespaces
+	d = NewDecoder()
+	d.SetNamespacePrefix("[")
+	d.SetNamespaceSuffix("]")
+
+	test = validatedUser{}
+	err = d.Decode(&test, url.Values{"name": []string{"joeybloggs"}, "tenant": []string{"acme"}, "Address[0][street]": []string{"main"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Address[0][phone]"].Error(), "Missing Required Field Namespace 'Address[0][phone]'")
+}
+
+type hookedItem struct {
+	Price    int `form:"price"`
+	Quantity int `form:"qty"`
+	Total    int `form:"-"`
+	keys     []string
+}
+
+func (i *hookedItem) BeforeDecode(values url.Values) {
+	for k := range values {
+		i.keys = append(i.keys, k)
+	}
+	sort.Strings(i.keys)
+}
+
+func (i *hookedItem) AfterDecode() error {
+	if i.Quantity < 0 {
+		return &FieldError{Field: "Quantity", Err: errors.New("negative quantity")}
+	}
+
+	i.Total = i.Price * i.Quantity
+	return nil
+}
+
+type hookedOrder struct {
+	Email string       `form:"email"`
+	Items []hookedItem `form:"items"`
+	Total int          `form:"-"`
+}
+
+func (o *hookedOrder) AfterDecode() error {
+	o.Email = strings.ToLower(o.Email)
+	for _, i := range o.Items {
+		o.Total += i.Total
+	}
+
+	if o.Total > 100 {
+		return errors.New("order too large")
+	}
+	return nil
+}
+
+func TestDecoderHooks(t *testing.T) {
+	values := url.Values{
+		"email":          []string{"Joey@Example.com"},
+		"items[0].price": []string{"2"},
+		"items[0].qty":   []string{"3"},
+		"items[1].price": []string{"4"},
+		"items[1].qty":   []string{"-1"},
+	}
+
+	var test hookedOrder
+	d := NewDecoder()
+	d.SetStrict(true)
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["items[1].qty"].Error(), "negative quantity")
+	assert.Equal(t, test.Email, "joey@example.com")
+	assert.Equal(t, test.Items[0].keys, []string{"price", "qty"})
+	assert.Equal(t, test.Items[0].Total, 6)
+	assert.Equal(t, test.Items[1].Total, 0)
+	assert.Equal(t, test.Total, 6)
+
+	test = hookedOrder{}
+	err = d.Decode(&test, url.Values{"items[0].price": []string{"101"}, "items[0].qty": []string{"1"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs[""].Error(), "order too large")
+}
+
+type normalizedSignup struct {
+	Email string `form:"email"`
+	Plan  string `form:"plan"`
+}
+
+func (s *normalizedSignup) BeforeDecode(values url.Values) {
+	values.Set("email", strings.ToLower(strings.TrimSpace(values.Get("email"))))
+	delete(values, "honeypot")
+	if values.Get("plan") == "" {
+		values.Set("plan", "free")
+	}
+}
+
+func TestDecoderBeforeDecodeValues(t *testing.T) {
+	type Test struct {
+		Owner   normalizedSignup   `form:"owner"`
+		Signups []normalizedSignup `form:"signups"`
+	}
+
+	values := url.Values{
+		"owner.email":         []string{"Owner@Example.com"},
+		"signups[0].email":    []string{" Joey@Example.com "},
+		"signups[0].honeypot": []string{"bot"},
+		"signups[1].email":    []string{"Bloggs@Example.com"},
+		"signups[1].plan":     []string{"pro"},
+	}
+
+	var test Test
+	d := NewDecoder()
+	d.SetStrict(true)
+	err := d.Decode(&test, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Owner, normalizedSignup{Email: "owner@example.com", Plan: "free"})
+	assert.Equal(t, test.Signups, []normalizedSignup{
+		{Email: "joey@example.com", Plan: "free"},
+		{Email: "bloggs@example.com", Plan: "pro"},
+	})
+
+	// the values passed in are left as they are
+	assert.Equal(t, values["signups[0].email"], []string{" Joey@Example.com "})
+	assert.Equal(t, len(values), 5)
+
+	var signup normalizedSignup
+	err = d.Decode(&signup, url.Values{"email": []string{"Root@Example.com"}, "honeypot": []string{"bot"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, signup, normalizedSignup{Email: "root@example.com", Plan: "free"})
+
+	test = Test{}
+	d.SetKeySyntax(KeySyntaxBracket)
+	err = d.Decode(&test, url.Values{"owner[email]": []string{"Owner@Example.com"}, "signups[0][plan]": []string{"pro"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Owner, normalizedSignup{Email: "owner@example.com", Plan: "free"})
+	assert.Equal(t, test.Signups, []normalizedSignup{{Plan: "pro"}})
+}
+
+func TestDecoderContext(t *testing.T) {
+	type ctxKey struct{}
+
+	type user struct {
+		ID   int
+		Name string
+	}
+
+	type Test struct {
+		Owner   *user   `form:"owner"`
+		Members []*user `form:"members,omitempty,case=upper"`
+		Root    string
+	}
+
+	users := map[string]*user{"1": {ID: 1, Name: "joey"}, "2": {ID: 2, Name: "bloggs"}}
+	ctx := context.WithValue(context.Background(), ctxKey{}, users)
+
+	var infos []FieldInfo
+	d := NewDecoder()
+	d.RegisterCustomTypeFuncContext(func(ctx context.Context, info FieldInfo) (interface{}, error) {
+		infos = append(infos, info)
+		users, _ := ctx.Value(ctxKey{}).(map[string]*user)
+		u, ok := users[info.Values[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown user '%s'", info.Values[0])
+		}
+
+		for _, opt := range info.Options {
+			if opt == "case=upper" {
+				return &user{ID: u.ID, Name: strings.ToUpper(u.Name)}, nil
+			}
+		}
+		return u, nil
+	}, &user{})
+
+	var test Test
+	err := d.DecodeContext(ctx, &test, url.Values{"owner": []string{"1"}, "members[0]": []string{"2"}, "members[1]": []string{"3"}})
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["members[1]"].Error(), "unknown user '3'")
+	assert.Equal(t, test.Owner, users["1"])
+	assert.Equal(t, test.Members[0], &user{ID: 2, Name: "BLOGGS"})
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Namespace < infos[j].Namespace })
+	assert.Equal(t, len(infos), 3)
+	assert.Equal(t, infos[0].Namespace, "members[0]")
+	assert.Equal(t, infos[0].Field.Name, "Members")
+	assert.Equal(t, infos[0].Options, []string{"omitempty", "case=upper"})
+	assert.Equal(t, infos[2].Namespace, "owner")
+	assert.Equal(t, infos[2].Field.Name, "Owner")
+	assert.Equal(t, infos[2].Values, []string{"1"})
+	assert.Equal(t, len(infos[2].Options), 0)
+
+	// without a context
+	var u *user
+	err = d.Decode(&u, url.Values{"": []string{"1"}})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.(DecodeErrors)[""].Error(), "unknown user '1'")
+	assert.Equal(t, infos[len(infos)-1].Field.Name, "")
+}
+
+func TestDecoderTimeOptions(t *testing.T) {
+	type Test struct {
+		DOB       time.Time    `form:"dob,layout=2006-01-02"`
+		Local     time.Time    `form:"local,layout=2006-01-02T15:04"`
+		Fallback  []time.Time  `form:"fallback,layout=2006-01-02|01/02/2006"`
+		Unix      time.Time    `form:"unix,unix"`
+		UnixMilli *time.Time   `form:"unixmilli,unixmilli"`
+		Default   time.Time    `form:"default"`
+		Bad       time.Time    `form:"bad,layout=2006-01-02"`
+		Defaulted time.Time    `form:"defaulted,layout=2006-01-02,default=2020-01-02"`
+		Nested    []*time.Time `form:"nested,unix"`
+	}
+
+	values := url.Values{
+		"dob":       []string{"2026-10-18"},
+		"local":     []string{"2026-10-18T09:30"},
+		"fallback":  []string{"2026-10-18", "10/19/2026"},
+		"unix":      []string{"1600000000"},
+		"unixmilli": []string{"1600000000123"},
+		"default":   []string{"2026-10-18T09:30:00Z"},
+		"bad":       []string{"18/10/2026"},
+		"nested":    []string{"0"},
+	}
+
+	loc := time.FixedZone("X", 3600)
+	bad := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d := NewDecoder()
+	d.SetTimeLocation(loc)
+
+	test := Test{Bad: bad}
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["bad"].Error(), "Invalid Time Value '18/10/2026' Type 'time.Time' Namespace 'bad'")
+	assert.Equal(t, test.Bad, bad)
+	assert.Equal(t, test.DOB, time.Date(2026, 10, 18, 0, 0, 0, 0, loc))
+	assert.Equal(t, test.Local, time.Date(2026, 10, 18, 9, 30, 0, 0, loc))
+	assert.Equal(t, test.Fallback, []time.Time{time.Date(2026, 10, 18, 0, 0, 0, 0, loc), time.Date(2026, 10, 19, 0, 0, 0, 0, loc)})
+	assert.Equal(t, test.Unix, time.Unix(1600000000, 0).In(loc))
+	assert.Equal(t, *test.UnixMilli, time.UnixMilli(1600000000123).In(loc))
+	assert.Equal(t, test.Default, time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+	assert.Equal(t, test.Defaulted, time.Date(2020, 1, 2, 0, 0, 0, 0, loc))
+	assert.Equal(t, *test.Nested[0], time.Unix(0, 0).In(loc))
+
+	// decoder layouts
+	d = NewDecoder()
+	d.SetTimeLayouts("2006-01-02", time.RFC3339)
+
+	var tm time.Time
+	err = d.Decode(&tm, url.Values{"": []string{"2026-10-18"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tm, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+
+	err = d.Decode(&tm, url.Values{"": []string{"2026-10-18T09:30:00Z"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tm, time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+
+	type BadOption struct {
+		Value string `form:"value,unix"`
+	}
+
+	var badOption BadOption
+	assert.PanicMatches(t, func() { _ = d.Decode(&badOption, url.Values{}) }, "form: unix is not supported for field 'Value' of type 'string'")
+}
+
+func TestDecoderDuration(t *testing.T) {
+	type Test struct {
+		Timeout  time.Duration
+		Ptr      *time.Duration
+		Slice    []time.Duration
+		Map      map[time.Duration]time.Duration
+		Plain    time.Duration
+		Fraction time.Duration
+		Bad      time.Duration
+		BadKey   map[time.Duration]int
+		Int      int64
+	}
+
+	values := url.Values{
+		"Timeout":   []string{"30s"},
+		"Ptr":       []string{"1h5m"},
+		"Slice":     []string{"5m", "-1.5s"},
+		"Map[1m]":   []string{"2m"},
+		"Plain":     []string{"30000000000"},
+		"Bad":       []string{"soon"},
+		"BadKey[x]": []string{"1"},
+		"Int":       []string{"30"},
+	}
+
+	var test Test
+	d := NewDecoder()
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs["Bad"].Error(), "Invalid Duration Value 'soon' Type 'time.Duration' Namespace 'Bad'")
+	assert.Equal(t, errs["BadKey"].Error(), "Invalid Duration Value 'x' Type 'time.Duration' Namespace 'BadKey'")
+	assert.Equal(t, test.Timeout, 30*time.Second)
+	assert.Equal(t, *test.Ptr, time.Hour+5*time.Minute)
+	assert.Equal(t, test.Slice, []time.Duration{5 * time.Minute, -1500 * time.Millisecond})
+	assert.Equal(t, test.Map, map[time.Duration]time.Duration{time.Minute: 2 * time.Minute})
+	assert.Equal(t, test.Plain, 30*time.Second)
+	assert.Equal(t, test.Int, int64(30))
+
+	// plain numbers in seconds
+	d.SetDurationUnit(time.Second)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"Plain": []string{"30"}, "Fraction": []string{"1.5"}, "Bad": []string{"9223372037"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Bad"].Error(), "Invalid Duration Value '9223372037' Type 'time.Duration' Namespace 'Bad'")
+	assert.Equal(t, test.Plain, 30*time.Second)
+	assert.Equal(t, test.Fraction, 1500*time.Millisecond)
+
+	// only duration syntax
+	d.SetDurationUnit(0)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"Plain": []string{"30"}, "Timeout": []string{"1m"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Plain"].Error(), "Invalid Duration Value '30' Type 'time.Duration' Namespace 'Plain'")
+	assert.Equal(t, test.Timeout, time.Minute)
+}
+
+func TestDecoderComplexAndBig(t *testing.T) {
+	type Test struct {
+		C64      complex64
+		C128     *complex128
+		CMap     map[complex128]complex64
+		BadC     complex64
+		Int      big.Int
+		Float    big.Float
+		FloatPtr *big.Float
+		Rat      *big.Rat
+		Rats     []big.Rat
+		IntMap   map[string]*big.Int
+		BadFloat big.Float
+	}
+
+	values := url.Values{
+		"C64":       []string{"1.5+2i"},
+		"C128":      []string{"(3-4.25i)"},
+		"CMap[1i]":  []string{"2"},
+		"BadC":      []string{"i1"},
+		"Int":       []string{"123456789012345678901234567890"},
+		"Float":     []string{"12345678901234567890.123456789012345678901"},
+		"FloatPtr":  []string{"0.1"},
+		"Rat":       []string{"1/3"},
+		"Rats":      []string{"0.1", "-2/4"},
+		"IntMap[a]": []string{"-99999999999999999999"},
+		"BadFloat":  []string{"1.2.3"},
+	}
+
+	var test Test
+	err := NewDecoder().Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs["BadC"].Error(), "Invalid Complex Value 'i1' Type 'complex64' Namespace 'BadC'")
+	assert.Equal(t, errs["BadFloat"].Error(), "Invalid Value '1.2.3' Type 'big.Float' Namespace 'BadFloat'")
+	assert.Equal(t, test.C64, complex64(1.5+2i))
+	assert.Equal(t, *test.C128, 3-4.25i)
+	assert.Equal(t, test.CMap, map[complex128]complex64{1i: 2})
+	assert.Equal(t, test.Int.String(), "123456789012345678901234567890")
+	assert.Equal(t, test.Float.Text('f', 21), "12345678901234567890.123456789012345678901")
+	assert.Equal(t, test.FloatPtr.Text('g', -1), "0.1")
+	assert.Equal(t, test.Rat.String(), "1/3")
+	assert.Equal(t, test.Rats[0].String(), "1/10")
+	assert.Equal(t, test.Rats[1].String(), "-1/2")
+	assert.Equal(t, test.IntMap["a"].String(), "-99999999999999999999")
+
+	// as the value passed to Decode
+	var i big.Int
+	err = NewDecoder().Decode(&i, url.Values{"": []string{"18446744073709551616"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, i.String(), "18446744073709551616")
+
+	// the precision of a big.Float is capped
+	var f big.Float
+	err = NewDecoder().Decode(&f, url.Values{"": []string{"0." + strings.Repeat("7", 19700)}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, f.Prec(), uint(65449))
+
+	err = NewDecoder().Decode(&f, url.Values{"": []string{"0." + strings.Repeat("7", 20000)}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, errors.Is(errs[""], ErrInvalidValue), true)
+	assert.Equal(t, errors.Is(errs[""], ErrBigFloatPrecision), true)
+}
+
+func TestDecoderStdlibTypes(t *testing.T) {
+	type Test struct {
+		AddrPort netip.AddrPort
+		URL      *url.URL
+		URLs     []url.URL
+		Email    mail.Address
+		BadURL   *url.URL
+		BadEmail mail.Address
+		ID       [16]byte `form:"id,uuid"`
+		Hash     [4]byte  `form:"hash,hex"`
+		IDs      []*[16]byte
+		Bytes    [2]byte
+		IDMap    map[[4]byte]int
+		BadID    [16]byte `form:"bad_id,uuid"`
+	}
+
+	values := url.Values{
+		"AddrPort":        []string{"127.0.0.1:8080"},
+		"URL":             []string{"https://example.com/path?q=1"},
+		"URLs":            []string{"/a", "mailto:joey@example.com"},
+		"Email":           []string{"Joey Bloggs <joey@example.com>"},
+		"BadURL":          []string{"%zz"},
+		"BadEmail":        []string{"joey"},
+		"id":              []string{"123e4567-e89b-12d3-a456-426614174000"},
+		"hash":            []string{"DEADBEEF"},
+		"IDs":             []string{"00000000000000000000000000000001"},
+		"IDMap[0a0b0c0d]": []string{"1"},
+		"bad_id":          []string{"123e4567"},
+	}
+
+	d := NewDecoder()
+	d.SetByteArrayFormat(ByteArrayHex)
+
+	var test Test
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 3)
+	assert.Equal(t, errs["BadURL"].Error(), "Invalid Value '%zz' Type 'url.URL' Namespace 'BadURL'")
+	assert.Equal(t, errs["BadEmail"].Error(), "Invalid Value 'joey' Type 'mail.Address' Namespace 'BadEmail'")
+	assert.Equal(t, errs["bad_id"].Error(), "Invalid Value '123e4567' Type '[16]uint8' Namespace 'bad_id'")
+	assert.Equal(t, test.AddrPort, netip.MustParseAddrPort("127.0.0.1:8080"))
+	assert.Equal(t, test.URL.String(), "https://example.com/path?q=1")
+	assert.Equal(t, test.URL.Host, "example.com")
+	assert.Equal(t, len(test.URLs), 2)
+	assert.Equal(t, test.URLs[1].Scheme, "mailto")
+	assert.Equal(t, test.Email, mail.Address{Name: "Joey Bloggs", Address: "joey@example.com"})
+	assert.Equal(t, test.BadURL, nil)
+	assert.Equal(t, test.ID, [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00})
+	assert.Equal(t, test.Hash, [4]byte{0xde, 0xad, 0xbe, 0xef})
+	assert.Equal(t, *test.IDs[0], [16]byte{15: 1})
+	assert.Equal(t, test.IDMap, map[[4]byte]int{{10, 11, 12, 13}: 1})
+
+	// byte arrays are decoded a value per element by default
+	test = Test{}
+	err = NewDecoder().Decode(&test, url.Values{"Bytes": []string{"1", "2"}, "hash": []string{"deadbeef"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Bytes, [2]byte{1, 2})
+	assert.Equal(t, test.Hash, [4]byte{0xde, 0xad, 0xbe, 0xef})
+
+	type BadTag struct {
+		Name string `form:"name,uuid"`
+	}
+
+	var bt BadTag
+	assert.PanicMatches(t, func() { _ = NewDecoder().Decode(&bt, url.Values{}) }, "form: uuid is not supported for field 'Name' of type 'string'")
+}
+
+// scannedStatus implements sql.Scanner, storing NULL as "unknown".
+type scannedStatus string
+
+func (s *scannedStatus) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = "unknown"
+	case string:
+		if v != "open" && v != "closed" {
+			return fmt.Errorf("invalid status %q", v)
+		}
+		*s = scannedStatus(v)
+	default:
+		return fmt.Errorf("unsupported type %T", src)
+	}
+
+	return nil
+}
+
+func (s scannedStatus) Value() (driver.Value, error) {
+	if s == "unknown" {
+		return nil, nil
+	}
+
+	return string(s), nil
+}
+
+func TestDecoderSQL(t *testing.T) {
+	type Test struct {
+		Name     sql.NullString
+		Age      sql.NullInt64
+		Score    *sql.NullFloat64
+		Active   sql.NullBool
+		Born     sql.NullTime `form:"born,layout=2006-01-02"`
+		Count    sql.Null[int]
+		Missing  sql.NullString
+		Empty    sql.NullInt32
+		Ages     []sql.NullInt16
+		BadAge   sql.NullInt64
+		Status   scannedStatus
+		Statuses []scannedStatus
+	}
+
+	values := url.Values{
+		"Name":     []string{"joeybloggs"},
+		"Age":      []string{"3"},
+		"Score":    []string{"1.5"},
+		"Active":   []string{"true"},
+		"born":     []string{"2024-02-29"},
+		"Count":    []string{"7"},
+		"Empty":    []string{""},
+		"Ages":     []string{"1", ""},
+		"BadAge":   []string{"x"},
+		"Status":   []string{"open"},
+		"Statuses": []string{"closed", "", "other"},
+	}
+
+	test := Test{Empty: sql.NullInt32{Int32: 1, Valid: true}}
+	err := NewDecoder().Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["BadAge"].Error(), "Invalid Integer Value 'x' Type 'int64' Namespace 'BadAge'")
+	assert.Equal(t, test.Name, sql.NullString{String: "joeybloggs", Valid: true})
+	assert.Equal(t, test.Age, sql.NullInt64{Int64: 3, Valid: true})
+	assert.Equal(t, *test.Score, sql.NullFloat64{Float64: 1.5, Valid: true})
+	assert.Equal(t, test.Active, sql.NullBool{Bool: true, Valid: true})
+	assert.Equal(t, test.Born, sql.NullTime{Time: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), Valid: true})
+	assert.Equal(t, test.Count, sql.Null[int]{V: 7, Valid: true})
+	assert.Equal(t, test.Missing, sql.NullString{})
+	assert.Equal(t, test.Empty, sql.NullInt32{})
+	assert.Equal(t, test.Ages, []sql.NullInt16{{Int16: 1, Valid: true}, {}})
+	assert.Equal(t, test.BadAge, sql.NullInt64{})
+
+	// without the bridge scanners are decoded by their kind
+	assert.Equal(t, test.Status, scannedStatus("open"))
+	assert.Equal(t, test.Statuses, []scannedStatus{"closed", "", "other"})
+
+	d := NewDecoder()
+	d.SetSQLBridge(true)
+
+	test = Test{}
+	err = d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs["Statuses"].Error(), "Invalid Value 'other' Type 'form.scannedStatus' Namespace 'Statuses'")
+	assert.Equal(t, errs["Statuses"].(*FieldError).Err.Error(), `invalid status "other"`)
+	assert.Equal(t, test.Name, sql.NullString{String: "joeybloggs", Valid: true})
+	assert.Equal(t, test.Status, scannedStatus("open"))
+	assert.Equal(t, test.Statuses, []scannedStatus{"closed", "unknown", ""})
+
+	// as the value passed to Decode
+	var ni sql.NullInt64
+	err = NewDecoder().Decode(&ni, url.Values{"": []string{"5"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ni, sql.NullInt64{Int64: 5, Valid: true})
+}
+
+func TestDecoderBytes(t *testing.T) {
+	type Blob []byte
+
+	type Test struct {
+		Raw     []byte
+		Blob    Blob
+		Ptr     *[]byte
+		Sig     []byte            `form:"sig,base64"`
+		URLSig  []byte            `form:"url_sig,base64"`
+		Hex     []byte            `form:"hex,hex"`
+		Text    []byte            `form:"text,raw"`
+		Files   [][]byte          `form:"files,base64"`
+		Map     map[string][]byte `form:"map,hex"`
+		Empty   []byte
+		Default []byte `form:"default,default=a|b"`
+		BadSig  []byte `form:"bad_sig,base64"`
+	}
+
+	values := url.Values{
+		"Raw":       []string{"hi"},
+		"Blob":      []string{"blob"},
+		"Ptr":       []string{"ptr"},
+		"sig":       []string{"aGk/Pz4+"},
+		"url_sig":   []string{"aGk_Pz4-"},
+		"hex":       []string{"DEADBEEF"},
+		"text":      []string{"aGk="},
+		"files":     []string{"YQ==", "Yg"},
+		"map[a]":    []string{"0102"},
+		"Empty":     []string{""},
+		"bad_sig":   []string{"!"},
+		"Raw.Extra": []string{"x"},
+	}
+
+	var test Test
+	err := NewDecoder().Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["bad_sig"].Error(), "Invalid Value '!' Type '[]uint8' Namespace 'bad_sig'")
+	assert.Equal(t, test.Raw, []byte("hi"))
+	assert.Equal(t, test.Blob, Blob("blob"))
+	assert.Equal(t, *test.Ptr, []byte("ptr"))
+	assert.Equal(t, test.Sig, []byte("hi??>>"))
+	assert.Equal(t, test.URLSig, []byte("hi??>>"))
+	assert.Equal(t, test.Hex, []byte{0xde, 0xad, 0xbe, 0xef})
+	assert.Equal(t, test.Text, []byte("aGk="))
+	assert.Equal(t, test.Files, [][]byte{[]byte("a"), []byte("b")})
+	assert.Equal(t, test.Map, map[string][]byte{"a": {1, 2}})
+	assert.Equal(t, test.Empty, []byte{})
+	assert.Equal(t, test.Default, []byte("a|b"))
+
+	type BadTag struct {
+		Name []string `form:"name,base64"`
+	}
+
+	var bt BadTag
+	assert.PanicMatches(t, func() { _ = NewDecoder().Decode(&bt, url.Values{}) }, "form: base64 is not supported for field 'Name' of type '[]string'")
+}
+
+func TestDecoderSplit(t *testing.T) {
+	type Test struct {
+		IDs     []int       `form:"ids,split=,"`
+		Tags    []string    `form:"tags,split=|"`
+		Ptr     *[]float64  `form:"ptr,split=;"`
+		Times   []time.Time `form:"times,split=,,layout=2006-01-02"`
+		BadIDs  []int       `form:"bad_ids,split=,"`
+		Default []int       `form:"default,split=,,default=4|5"`
+		Names   []string
+	}
+
+	values := url.Values{
+		"ids":     []string{"1,2", "3"},
+		"tags":    []string{"a|b"},
+		"ptr":     []string{"1.5;2"},
+		"times":   []string{"2024-01-02,2024-01-03"},
+		"bad_ids": []string{"1,x,3"},
+		"Names":   []string{"a,b"},
+	}
+
+	var test Test
+	err := NewDecoder().Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["bad_ids[1]"].Error(), "Invalid Integer Value 'x' Type 'int' Namespace 'bad_ids[1]'")
+	assert.Equal(t, errs["bad_ids[1]"].(*FieldError).Field, "BadIDs[1]")
+	assert.Equal(t, test.IDs, []int{1, 2, 3})
+	assert.Equal(t, test.Tags, []string{"a", "b"})
+	assert.Equal(t, *test.Ptr, []float64{1.5, 2})
+	assert.Equal(t, test.Times, []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)})
+	assert.Equal(t, test.BadIDs, []int{1, 0, 3})
+	assert.Equal(t, test.Default, []int{4, 5})
+	assert.Equal(t, test.Names, []string{"a,b"})
+
+	// decoder-wide delimiter
+	d := NewDecoder()
+	d.SetSplitDelimiter(",")
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"Names": []string{"a,b", "c"}, "tags": []string{"a,b|c"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Names, []string{"a", "b", "c"})
+	assert.Equal(t, test.Tags, []string{"a,b", "c"})
+
+	type BadTag struct {
+		Name string `form:"name,split=,"`
+	}
+
+	var bt BadTag
+	assert.PanicMatches(t, func() { _ = NewDecoder().Decode(&bt, url.Values{}) }, "form: split is not supported for field 'Name' of type 'string'")
+}
+
+func TestDecoderEmptyBrackets(t *testing.T) {
+	type Item struct {
+		Name   string
+		Emails []string
+	}
+
+	type Test struct {
+		Tags   []string
+		IDs    []int
+		Items  []Item
+		Nested [][]int
+		Array  [3]string
+		Named  []string `form:"Named[]"`
+	}
+
+	values := url.Values{
+		"Tags[]":            []string{"a", "b"},
+		"IDs":               []string{"1"},
+		"IDs[]":             []string{"2", "x"},
+		"Items[0].Name":     []string{"first"},
+		"Items[].Name":      []string{"second", "third"},
+		"Items[].Emails[]":  []string{"a@example.com", "b@example.com"},
+		"Items[0].Emails[]": []string{"c@example.com"},
+		"Nested[][]":        []string{"1", "2"},
+		"Array[]":           []string{"a", "b"},
+		"Named[]":           []string{"n"},
+	}
+
+	var test Test
+	err := NewDecoder().Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["IDs"].Error(), "Invalid Integer Value 'x' Type 'int' Namespace 'IDs'")
+	assert.Equal(t, test.Tags, []string{"a", "b"})
+	assert.Equal(t, test.IDs, []int{1, 2, 0})
+	assert.Equal(t, test.Items, []Item{
+		{Name: "first", Emails: []string{"c@example.com"}},
+		{Name: "second", Emails: []string{"a@example.com"}},
+		{Name: "third", Emails: []string{"b@example.com"}},
+	})
+	assert.Equal(t, test.Nested, [][]int{{1}, {2}})
+	assert.Equal(t, test.Array, [3]string{"a", "b", ""})
+	assert.Equal(t, test.Named, []string{"n"})
+
+	// trailing brackets follow the explicit indexes of the key
+	test = Test{}
+	err = NewDecoder().Decode(&test, url.Values{"Tags[0]": []string{"z"}, "Tags[]": []string{"a", "b"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, test.Tags, []string{"z", "a", "b"})
+
+	// the values passed in are left unchanged
+	assert.Equal(t, values["IDs"], []string{"1"})
+	_, ok := values["Tags"]
+	assert.Equal(t, ok, false)
+
+	// keys are consumed through the keys they append to
+	d := NewDecoder()
+	d.SetStrict(true)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"Tags[]": []string{"a"}, "Items[].Name": []string{"b"}, "Unknown[]": []string{"c"}})
+	assert.NotEqual(t, err, nil)
+
+	errs = err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errors.Is(errs["Unknown[]"], ErrUnknownKey), true)
+	assert.Equal(t, test.Tags, []string{"a"})
+	assert.Equal(t, test.Items, []Item{{Name: "b"}})
+
+	// fields of the appended elements may be named in brackets too
+	type User struct {
+		Name   string
+		Emails []string
+		Meta   map[string]string
+		Best   *Item
+	}
+
+	type Form struct {
+		Items []Item
+		Users []User
+		Maps  []map[string]string
+	}
+
+	var form Form
+	err = d.Decode(&form, url.Values{
+		"Items[][Name]":           []string{"x"},
+		"Users[][Emails][]":       []string{"a@example.com", "b@example.com"},
+		"Users[][Name]":           []string{"joey", "bloggs"},
+		"Users[][Meta][Name]":     []string{"meta"},
+		"Users[][Best][Emails][]": []string{"best@example.com"},
+		"Maps[][Name]":            []string{"key"},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, form.Items, []Item{{Name: "x"}})
+	assert.Equal(t, form.Users, []User{
+		{Name: "joey", Emails: []string{"a@example.com"}, Meta: map[string]string{"Name": "meta"}, Best: &Item{Emails: []string{"best@example.com"}}},
+		{Name: "bloggs", Emails: []string{"b@example.com"}},
+	})
+	assert.Equal(t, form.Maps, []map[string]string{{"Name": "key"}})
+}
+
+func TestDecoderKeySyntax(t *testing.T) {
+	type Address struct {
+		Name  string
+		Zip   int
+		Lines []string
+	}
+
+	type User struct {
+		Name      string
+		Addresses []Address
+		Meta      map[string]int
+		Matrix    [][]int
+	}
+
+	type Test struct {
+		User User `form:"user"`
+	}
+
+	expected := User{
+		Name: "joeybloggs",
+		Addresses: []Address{
+			{Name: "home", Zip: 12345, Lines: []string{"1 Street", "Town"}},
+			{Name: "work", Lines: []string{"2 Road"}},
+		},
+		Meta:   map[string]int{"age": 3},
+		Matrix: [][]int{{1, 2}, {3}},
+	}
+
+	tests := []struct {
+		syntax KeySyntax
+		values url.Values
+		bad    string
+	}{
+		{
+			syntax: KeySyntaxMixed,
+			values: url.Values{
+				"user.Name":                  []string{"joeybloggs"},
+				"user.Addresses[0].Name":     []string{"home"},
+				"user.Addresses[0].Zip":      []string{"12345"},
+				"user.Addresses[0].Lines":    []string{"1 Street", "Town"},
+				"user.Addresses[1].Name":     []string{"work"},
+				"user.Addresses[1].Lines[0]": []string{"2 Road"},
+				"user.Meta[age]":             []string{"3"},
+				"user.Matrix[0]":             []string{"1", "2"},
+				"user.Matrix[1][0]":          []string{"3"},
+				"user.Addresses[1].Zip":      []string{"x"},
+			},
+			bad: "user.Addresses[1].Zip",
+		},
+		{
+			syntax: KeySyntaxBracket,
+			values: url.Values{
+				"user[Name]":                   []string{"joeybloggs"},
+				"user[Addresses][0][Name]":     []string{"home"},
+				"user[Addresses][0][Zip]":      []string{"12345"},
+				"user[Addresses][0][Lines][]":  []string{"1 Street", "Town"},
+				"user[Addresses][1][Name]":     []string{"work"},
+				"user[Addresses][1][Lines][0]": []string{"2 Road"},
+				"user[Meta][age]":              []string{"3"},
+				"user[Matrix][0]":              []string{"1", "2"},
+				"user[Matrix][1][0]":           []string{"3"},
+				"user[Addresses][1][Zip]":      []string{"x"},
+			},
+			bad: "user[Addresses][1][Zip]",
+		},
+		{
+			syntax: KeySyntaxDot,
+			values: url.Values{
+				"user.Name":                []string{"joeybloggs"},
+				"user.Addresses.0.Name":    []string{"home"},
+				"user.Addresses.0.Zip":     []string{"12345"},
+				"user.Addresses.0.Lines":   []string{"1 Street", "Town"},
+				"user.Addresses.1.Name":    []string{"work"},
+				"user.Addresses.1.Lines.0": []string{"2 Road"},
+				"user.Meta.age":            []string{"3"},
+				"user.Matrix.0":            []string{"1", "2"},
+				"user.Matrix.1.0":          []string{"3"},
+				"user.Addresses.1.Zip":     []string{"x"},
+			},
+			bad: "user.Addresses.1.Zip",
+		},
+	}
+
+	for _, tt := range tests {
+		d := NewDecoder()
+		d.SetKeySyntax(tt.syntax)
+
+		var test Test
+		err := d.Decode(&test, tt.values)
+		assert.NotEqual(t, err, nil)
+
+		errs := err.(DecodeErrors)
+		assert.Equal(t, len(errs), 1)
+		assert.Equal(t, errs[tt.bad].(*FieldError).Field, "User.Addresses[1].Zip")
+		assert.Equal(t, test.User, expected)
+	}
+}
+
+func TestDecoderKeyEscaping(t *testing.T) {
+	type Test struct {
+		Map    map[string]int
+		Tags   []string
+		Dotted string `form:"a.b"`
+	}
+
+	values := url.Values{
+		`Map[a\]b]`:   []string{"1"},
+		`Map[c\[\]]`:  []string{"2"},
+		`Map[\\]`:     []string{"3"},
+		`Map[e\.f]`:   []string{"4"},
+		"Tags[]":      []string{"x", "y"},
+		`a\.b`:        []string{"dotted"},
+		`Map[bad\]`:   []string{"5"},
+		`Unclosed\[x`: []string{"6"},
+	}
+
+	d := NewDecoder()
+	d.SetKeyEscaping(true)
+
+	var test Test
+	err := d.Decode(&test, values)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(DecodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errors.Is(errs[`Map[bad\]`], ErrMissingEndBracket), true)
+	assert.Equal(t, test.Map, map[string]int{"a]b": 1, "c[]": 2, `\`: 3, "e.f": 4})
+	assert.Equal(t, test.Tags, []string{"x", "y"})
+	assert.Equal(t, test.Dotted, "dotted")
+}
@@ -14,13 +14,16 @@ It has the following features:
   - Array honours the specified index.
     eg. if "Array[2]" is the only Array value passed down,
     it will be put at index 2;
-    if array isn't big enough a warning will be printed and value ignored.
+    if array isn't big enough the value is ignored and a warning
+    passed to the function registered with RegisterWarningFunc,
+    or an ErrArrayOverflow FieldError returned when SetArrayOverflowError is enabled.
   - Only creates objects as necessary.
     eg. if no `array` or `map` values are passed down,
     the `array` and `map` are left as their default values in the struct.
   - Allows for Custom Type registration.
   - Handles time.Time using RFC3339 time format by default,
-    but can easily be changed by registering a Custom Type, see below.
+    which can be changed per field with the `layout=`, `unix` and `unixmilli` tag options,
+    with SetTimeLayouts and SetTimeLocation or by registering a Custom Type, see below.
   - Handles Encoding & Decoding of almost all Go types.
     eg. can Decode into struct, array, map, int...
     and Encode a struct, array, map, int...
@@ -39,12 +42,32 @@ out of the box supported types
 
   - float32, float64
 
+  - complex64, complex128
+
+  - big.Int, big.Float and big.Rat
+
   - struct and anonymous struct
 
   - interface{}
 
   - time.Time` - by default using RFC3339
 
+  - time.Duration - using time.ParseDuration syntax eg. 30s, or plain numbers in the unit set with SetDurationUnit
+
+  - url.URL and mail.Address
+
+  - database/sql Null types eg. sql.NullString and sql.Null[T], an empty value is not Valid;
+    other sql.Scanner and driver.Valuer types are supported with SetSQLBridge
+
+  - types implementing encoding.TextUnmarshaler and encoding.TextMarshaler,
+    including as map keys eg. netip.Addr, netip.Prefix, netip.AddrPort and net.IP
+
+  - []byte as a single value, the raw text by default
+    or base64 or hex with the `base64` and `hex` tag options
+
+  - byte arrays eg. [16]byte as hex or UUID text, with SetByteArrayFormat
+    or the `hex` and `uuid` tag options
+
   - a `pointer` to one of the above types
 
   - slice, array
@@ -66,6 +89,10 @@ symbols
   - Use symbol `.` for separating fields/structs. (eg. `structfield.field`)
   - Use `[index or key]` for access to index of a slice/array or key for map.
     (eg. `arrayfield[0]`, `mapfield[keyvalue]`)
+  - Use `[]` to append to a slice/array. (eg. `Tags[]=a&Tags[]=b`, `Items[][Name]=x&Items[][Name]=y`)
+    each value of a key with `[]` before its end appends a new element,
+    whose fields may be written as `[Name]` or `.Name`;
+    Encoder.SetEmptyBrackets encodes repeated values this way.
 
 html
 
@@ -243,6 +270,83 @@ you can tell form to omit empty fields using `,omitempty` or `FieldName,omitempt
 	    Field2 string `form:"CustomFieldName,omitempty"`
 	}
 
+# Default Values
+
+the decoder fills fields whose key is missing from the input using `,default=` in the tag,
+slices and arrays take multiple values separated by '|'.
+Defaults are checked when the struct is first cached and panic if they do not convert.
+
+	type MyStruct struct {
+	    Page int      `form:"page,default=1"`
+	    Tags []string `form:"tags,default=new|popular"`
+	}
+
+# Required Fields
+
+the decoder reports a FieldError with the ErrRequired kind for every field tagged `,required`
+whose key, or any key under it, is missing; fields of nested structs and slice elements
+are only checked when that struct has values.
+
+	type MyStruct struct {
+	    Name string `form:"name,required"`
+	}
+
+# Constraints
+
+the decoder checks `min=`, `max=`, `len=`, `pattern=` and `oneof=` (values separated by '|')
+after a field is decoded; min and max compare numbers or the length of strings, slices and maps.
+
+	type MyStruct struct {
+	    Age    int    `form:"age,min=18,max=130"`
+	    Status string `form:"status,oneof=open|closed"`
+	}
+
+# Validation
+
+structs implementing Validator have Validate called once decoded, including nested structs that were set;
+the Field of any *FieldError returned, a Go field path, is rewritten to the form namespace of the field.
+
+	func (u *User) Validate() error {
+	    if u.Name == "" {
+	        return &form.FieldError{Field: "Name", Kind: form.ErrRequired}
+	    }
+	    return nil
+	}
+
+# Key Syntax
+
+SetKeySyntax on the Decoder and Encoder selects how the keys of nested values are written:
+KeySyntaxMixed, the default, eg. user.Addresses[0].Name; KeySyntaxBracket eg. user[Addresses][0][Name]
+and KeySyntaxDot eg. user.Addresses.0.Name, with which map keys must not contain '.'.
+
+# Key Escaping
+
+SetKeyEscaping on the Encoder escapes '.', '[', ']' and '\' in map keys and field names with a backslash
+eg. Map[a\]b] for the key "a]b", and the empty key as Map[\0] rather than Map[], which appends,
+and on the Decoder reads them back, so any string map key round-trips.
+
+# Anonymous Structs
+
+the Decoder fills embedded structs from both the flattened keys and the keys under their type name,
+SetAnonymousMode decodes from only the keys the Encoder writes in the same mode;
+with AnonymousEmbed fields of the same name are given the values of their key in the order the Encoder appends them.
+
+# Delimited Lists
+
+the `,split=` tag option, or SetSplitDelimiter, decodes each value of a slice as a list separated
+by the delimiter and encodes the elements joined with it; errors are set under the element's index eg. ids[1],
+including ErrSplitDelimiter for an element being encoded that contains the delimiter.
+
+	type MyStruct struct {
+	    IDs []int `form:"ids,split=,"`
+	}
+
+# Hooks
+
+structs, including nested structs and slice elements, may implement BeforeDecoder, AfterDecoder
+and BeforeEncoder; errors they return are recorded under the struct's namespace.
+BeforeDecode may modify the values it is passed, the struct's fields are decoded from them.
+
 # Notes
 
 To maximize compatibility with other systems the Encoder attempts
@@ -1,10 +1,13 @@
 package form
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,14 +16,123 @@ type encoder struct {
 	errs      EncodeErrors
 	values    url.Values
 	namespace []byte
+	path      []byte
+	embedded  bool
+	ctx       context.Context
+	// field and structType are the struct field being encoded, for FieldInfo
+	field      *cachedField
+	structType reflect.Type
+}
+
+// formatTime formats a time using the layout or unix options of the field being encoded,
+// falling back to the layout set on the Encoder.
+func (e *encoder) formatTime(t time.Time) string {
+	if e.e.timeLocation != nil {
+		t = t.In(e.e.timeLocation)
+	}
+
+	layout := e.e.timeLayout
+	if e.field != nil {
+		switch e.field.timeUnit {
+		case timeUnitSeconds:
+			return strconv.FormatInt(t.Unix(), 10)
+		case timeUnitMillis:
+			return strconv.FormatInt(t.UnixMilli(), 10)
+		}
+
+		if e.field.timeLayouts != nil {
+			layout = e.field.timeLayouts[0]
+		}
+	}
+
+	return t.Format(layout)
+}
+
+// formatDuration formats a duration using Duration.String, or as a number in the unit set on the Encoder.
+func (e *encoder) formatDuration(d time.Duration) string {
+	unit := e.e.durationUnit
+	switch {
+	case unit == 0:
+		return d.String()
+	case d%unit == 0:
+		return strconv.FormatInt(int64(d/unit), 10)
+	default:
+		return strconv.FormatFloat(float64(d)/float64(unit), 'f', -1, 64)
+	}
+}
+
+// splitDelimiter returns the delimiter slice elements are joined with, from the split option of the field being encoded
+// falling back to the delimiter set on the Encoder; blank when elements are not joined.
+func (e *encoder) splitDelimiter() string {
+	if e.field != nil && len(e.field.split) > 0 {
+		return e.field.split
+	}
+
+	return e.e.splitDelimiter
+}
+
+// formatDriverValue formats a value returned by a driver.Valuer, nil being encoded as an empty value.
+func (e *encoder) formatDriverValue(dv driver.Value) string {
+	switch t := dv.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return e.formatTime(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// byteArrayFormat returns the format of the array type using the hex or uuid option of the field being encoded,
+// falling back to the format set on the Encoder; arrays of other than bytes always use ByteArrayElements.
+func (e *encoder) byteArrayFormat(typ reflect.Type) ByteArrayFormat {
+	if typ.Elem().Kind() != reflect.Uint8 {
+		return ByteArrayElements
+	}
+
+	if e.field != nil && e.field.byteFormat != ByteArrayElements {
+		return e.field.byteFormat
+	}
+
+	return e.e.byteArrayFormat
+}
+
+// context returns the context passed to EncodeContext.
+func (e *encoder) context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+
+	return e.ctx
+}
+
+// fieldInfo describes the struct field being encoded for a context-aware custom type function.
+func (e *encoder) fieldInfo(namespace []byte) FieldInfo {
+	info := FieldInfo{Namespace: string(namespace)}
+	if e.field != nil {
+		info.Field = e.structType.Field(e.field.idx)
+		info.Options = e.field.options
+	}
+
+	return info
 }
 
 func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool) {
 	v, kind := ExtractType(key)
 	if e.e.customTypeFuncs != nil {
 		if cf, ok := e.e.customTypeFuncs[v.Type()]; ok {
-			if arr, err := cf(v.Interface()); err != nil {
-				e.setError(namespace, err)
+			if arr, err := cf(e, v, namespace); err != nil {
+				e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
 				return "", false
 			} else {
 				return arr[0], true
@@ -28,6 +140,16 @@ func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool)
 		}
 	}
 
+	if tm, ok := textMarshaler(v, methodsOf(v.Type())); ok {
+		text, err := marshalText(tm)
+		if err != nil {
+			e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
+			return "", false
+		}
+
+		return string(text), true
+	}
+
 	switch kind {
 	case reflect.Interface, reflect.Ptr:
 		return "", false
@@ -36,19 +158,61 @@ func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return strconv.FormatUint(v.Uint(), 10), true
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			return e.formatDuration(time.Duration(v.Int())), true
+		}
+
 		return strconv.FormatInt(v.Int(), 10), true
 	case reflect.Float32:
 		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true
 	case reflect.Float64:
 		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	case reflect.Complex64:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 64), true
+	case reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 128), true
 	case reflect.Bool:
 		return strconv.FormatBool(v.Bool()), true
+	case reflect.Array:
+		if f := e.byteArrayFormat(v.Type()); f != ByteArrayElements {
+			return formatByteArray(v, f), true
+		}
+
+		fallthrough
 	default:
-		e.setError(namespace, fmt.Errorf("Unsupported Map Key '%v' Namespace '%s'", v.String(), namespace))
+		fe := e.fieldError(namespace, ErrUnsupportedMapKey, v.Type(), nil)
+		fe.Value = fmt.Sprint(v)
+		e.setError(namespace, fe)
 		return "", false
 	}
 }
 
+// checkSplit sets an error for an element whose values contain the delimiter it is joined with,
+// as it would be decoded as several elements.
+func (e *encoder) checkSplit(current reflect.Value, namespace []byte, i int, sep string, vals []string) {
+	for _, val := range vals {
+		if strings.Contains(val, sep) {
+			ns := e.e.keySyntax.appendIndex(namespace[:len(namespace):len(namespace)], i)
+			fe := e.fieldError(ns, ErrSplitDelimiter, current.Type(), nil)
+			fe.Value = val
+			fe.Param = sep
+			e.setError(ns, fe)
+			return
+		}
+	}
+}
+
+// fieldError creates a FieldError for the namespace and the current Go field path.
+func (e *encoder) fieldError(namespace []byte, kind error, typ reflect.Type, err error) *FieldError {
+	return &FieldError{
+		Namespace: string(namespace),
+		Field:     string(e.path),
+		Type:      typ,
+		Kind:      kind,
+		Err:       err,
+	}
+}
+
 func (e *encoder) setError(namespace []byte, err error) {
 	if e.errs == nil {
 		e.errs = make(EncodeErrors)
@@ -68,11 +232,34 @@ func (e *encoder) setVal(namespace []byte, idx int, vals ...string) {
 	e.values[string(namespace)] = arr
 }
 
+// setSubValues sets the values returned by a Marshaler, with keys relative to the namespace.
+func (e *encoder) setSubValues(namespace []byte, vals url.Values) {
+	l := len(namespace)
+	for k, v := range vals {
+		namespace = namespace[:l]
+		switch {
+		case l == 0 || len(k) == 0 || k[0] == '[':
+			namespace = append(namespace, k...)
+		default:
+			// field name up until any index or map key, see Unmarshaler
+			i := strings.IndexByte(k, '[')
+			if i == -1 {
+				i = len(k)
+			}
+
+			namespace = append(namespace, e.e.namespacePrefix...)
+			namespace = append(namespace, k[:i]...)
+			namespace = append(namespace, e.e.namespaceSuffix...)
+			namespace = append(namespace, k[i:]...)
+		}
+
+		e.setVal(namespace, -1, v...)
+	}
+}
+
 func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx int, isOmitEmpty bool) {
 	if idx > -1 && current.Kind() == reflect.Ptr {
-		namespace = append(namespace, '[')
-		namespace = strconv.AppendInt(namespace, int64(idx), 10)
-		namespace = append(namespace, ']')
+		namespace = e.e.keySyntax.appendIndex(namespace, idx)
 		idx = -2
 	}
 
@@ -83,14 +270,12 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 	v, kind := ExtractType(current)
 	if e.e.customTypeFuncs != nil {
 		if cf, ok := e.e.customTypeFuncs[v.Type()]; ok {
-			if arr, err := cf(v.Interface()); err != nil {
-				e.setError(namespace, err)
+			if arr, err := cf(e, v, namespace); err != nil {
+				e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
 				return
 			} else {
 				if idx > -1 {
-					namespace = append(namespace, '[')
-					namespace = strconv.AppendInt(namespace, int64(idx), 10)
-					namespace = append(namespace, ']')
+					namespace = e.e.keySyntax.appendIndex(namespace, idx)
 				}
 
 				e.setVal(namespace, idx, arr...)
@@ -99,6 +284,46 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		}
 	}
 
+	m := methodsOf(v.Type())
+	if fm, ok := formMarshaler(v, m); ok {
+		vals, err := fm.MarshalForm()
+		if err != nil {
+			e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
+			return
+		}
+
+		if idx > -1 {
+			namespace = e.e.keySyntax.appendIndex(namespace, idx)
+		}
+
+		e.setSubValues(namespace, vals)
+		return
+	}
+
+	if tm, ok := textMarshaler(v, m); ok {
+		text, err := marshalText(tm)
+		if err != nil {
+			e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
+			return
+		}
+
+		e.setVal(namespace, idx, string(text))
+		return
+	}
+
+	if e.e.sqlBridge {
+		if vr, ok := valuer(v, m); ok {
+			dv, err := vr.Value()
+			if err != nil {
+				e.setError(namespace, e.fieldError(namespace, nil, v.Type(), err))
+				return
+			}
+
+			e.setVal(namespace, idx, e.formatDriverValue(dv))
+			return
+		}
+	}
+
 	switch kind {
 	case reflect.Ptr, reflect.Interface, reflect.Invalid:
 		return
@@ -107,67 +332,152 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		e.setVal(namespace, idx, strconv.FormatUint(v.Uint(), 10))
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			e.setVal(namespace, idx, e.formatDuration(time.Duration(v.Int())))
+			return
+		}
+
 		e.setVal(namespace, idx, strconv.FormatInt(v.Int(), 10))
 	case reflect.Float32:
 		e.setVal(namespace, idx, strconv.FormatFloat(v.Float(), 'f', -1, 32))
 	case reflect.Float64:
 		e.setVal(namespace, idx, strconv.FormatFloat(v.Float(), 'f', -1, 64))
+	case reflect.Complex64:
+		e.setVal(namespace, idx, strconv.FormatComplex(v.Complex(), 'f', -1, 64))
+	case reflect.Complex128:
+		e.setVal(namespace, idx, strconv.FormatComplex(v.Complex(), 'f', -1, 128))
 	case reflect.Bool:
 		e.setVal(namespace, idx, strconv.FormatBool(v.Bool()))
 	case reflect.Slice, reflect.Array:
+		// byte slices are a single value
+		if kind == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// nil elements of a slice are kept as empty values so the following elements keep their position
+			if v.IsNil() && idx < 0 {
+				return
+			}
+
+			var format uint8
+			if e.field != nil {
+				format = e.field.bytesFormat
+			}
+
+			e.setVal(namespace, idx, formatBytes(v.Bytes(), format))
+			return
+		}
+
+		if f := e.byteArrayFormat(v.Type()); kind == reflect.Array && f != ByteArrayElements {
+			if idx > -1 {
+				namespace = e.e.keySyntax.appendIndex(namespace, idx)
+			}
+
+			e.setVal(namespace, idx, formatByteArray(v, f))
+			return
+		}
+
+		pl := len(e.path)
 		if idx == -1 {
+			var sep string
+			if kind == reflect.Slice {
+				sep = e.splitDelimiter()
+			}
+
+			n := len(e.values[string(namespace)])
 			for i := 0; i < v.Len(); i++ {
+				m := len(e.values[string(namespace)])
+				e.path = append(strconv.AppendInt(append(e.path[:pl], '['), int64(i), 10), ']')
 				e.setFieldByType(v.Index(i), namespace, i, false)
+				if len(sep) > 0 {
+					e.checkSplit(v.Index(i), namespace, i, sep, e.values[string(namespace)][m:])
+				}
+			}
+
+			e.path = e.path[:pl]
+			// join the elements encoded as values of the namespace, elements at an index eg. pointers are kept
+			if len(sep) > 0 {
+				if vals := e.values[string(namespace)]; len(vals) > n+1 {
+					e.values[string(namespace)] = append(vals[:n], strings.Join(vals[n:], sep))
+				}
+			} else if e.e.emptyBrackets && len(namespace) > 0 {
+				// move them under the namespace with empty brackets eg. Tags[]
+				if vals := e.values[string(namespace)]; len(vals) > n {
+					if n == 0 {
+						delete(e.values, string(namespace))
+					} else {
+						e.values[string(namespace)] = vals[:n:n]
+					}
+
+					e.setVal(append(namespace, "[]"...), -1, vals[n:]...)
+				}
 			}
 
 			return
 		}
 
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.e.keySyntax.appendIndex(namespace, idx)
 		}
 
-		namespace = append(namespace, '[')
 		l := len(namespace)
 		for i := 0; i < v.Len(); i++ {
-			namespace = namespace[:l]
-			namespace = strconv.AppendInt(namespace, int64(i), 10)
-			namespace = append(namespace, ']')
+			namespace = e.e.keySyntax.appendIndex(namespace[:l], i)
+			e.path = append(strconv.AppendInt(append(e.path[:pl], '['), int64(i), 10), ']')
 			e.setFieldByType(v.Index(i), namespace, -2, false)
 		}
+
+		e.path = e.path[:pl]
 	case reflect.Map:
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.e.keySyntax.appendIndex(namespace, idx)
 		}
 
 		var s string
 		var valid bool
 		l := len(namespace)
+		pl := len(e.path)
 		for _, key := range v.MapKeys() {
 			namespace = namespace[:l]
+			e.path = e.path[:pl]
 			if s, valid = e.getMapKey(key, namespace); !valid {
 				continue
 			}
 
-			namespace = append(namespace, '[')
-			namespace = append(namespace, s...)
-			namespace = append(namespace, ']')
+			if e.e.escapeKeys {
+				s = escapeKey(s)
+			}
+
+			namespace = e.e.keySyntax.appendKey(namespace, s)
+			e.path = append(append(append(e.path, '['), s...), ']')
 			e.setFieldByType(v.MapIndex(key), namespace, -2, false)
 		}
+
+		e.path = e.path[:pl]
 	case reflect.Struct:
-		// if get here then no custom time function declared so use RFC3339 by default
+		// if get here then no custom time function declared so use the field or encoder layout, RFC3339 by default
 		if v.Type() == timeType {
 			if idx > -1 {
-				namespace = append(namespace, '[')
-				namespace = strconv.AppendInt(namespace, int64(idx), 10)
-				namespace = append(namespace, ']')
+				namespace = e.e.keySyntax.appendIndex(namespace, idx)
+			}
+
+			e.setVal(namespace, idx, e.formatTime(v.Interface().(time.Time)))
+			return
+		}
+
+		if sqlNull(v.Type()) {
+			if !v.Field(1).Bool() {
+				e.setVal(namespace, idx, "")
+				return
+			}
+
+			e.setFieldByType(v.Field(0), namespace, idx, false)
+			return
+		}
+
+		if v.Type() == urlType || v.Type() == mailAddressType {
+			if idx > -1 {
+				namespace = e.e.keySyntax.appendIndex(namespace, idx)
 			}
 
-			e.setVal(namespace, idx, v.Interface().(time.Time).Format(time.RFC3339))
+			e.setVal(namespace, idx, formatValue(v))
 			return
 		}
 
@@ -177,9 +487,7 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		}
 
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.e.keySyntax.appendIndex(namespace, idx)
 		}
 
 		e.traverseStruct(v, namespace, -2)
@@ -190,7 +498,6 @@ func (e *encoder) traverseStruct(v reflect.Value, namespace []byte, idx int) {
 	typ := v.Type()
 	l := len(namespace)
 	first := l == 0
-
 	// anonymous structs will still work for caching as the whole definition is stored
 	// including tags
 	s, ok := e.e.structCache.Get(typ)
@@ -198,21 +505,59 @@ func (e *encoder) traverseStruct(v reflect.Value, namespace []byte, idx int) {
 		s = e.e.structCache.parseStruct(e.e.mode, v, typ, e.e.tagName)
 	}
 
-	for _, f := range s.fields {
+	// embedded structs have their hook called through the struct embedding them
+	if !e.embedded && s.methods.pointer(methodBeforeEncoder) {
+		if !v.CanAddr() {
+			c := reflect.New(typ).Elem()
+			c.Set(v)
+			v = c
+		}
+
+		if err := v.Addr().Interface().(BeforeEncoder).BeforeEncode(); err != nil {
+			e.setError(namespace, e.fieldError(namespace, nil, typ, err))
+			return
+		}
+	}
+
+	e.embedded = false
+
+	pl := len(e.path)
+	field, structType := e.field, e.structType
+	e.structType = typ
+	for i := range s.fields {
+		f := &s.fields[i]
+		e.field = f
 		namespace = namespace[:l]
+		e.path = e.path[:pl]
+		if pl > 0 {
+			e.path = append(e.path, '.')
+		}
+
+		e.path = append(e.path, f.fieldName...)
+		e.embedded = f.isAnonymous
 		if f.isAnonymous && e.e.embedAnonymous {
 			e.setFieldByType(v.Field(f.idx), namespace, idx, f.isOmitEmpty)
+			e.embedded = false
 			continue
 		}
 
+		name := f.name
+		if e.e.escapeKeys {
+			name = escapeKey(name)
+		}
+
 		if first {
-			namespace = append(namespace, f.name...)
+			namespace = append(namespace, name...)
 		} else {
 			namespace = append(namespace, e.e.namespacePrefix...)
-			namespace = append(namespace, f.name...)
+			namespace = append(namespace, name...)
 			namespace = append(namespace, e.e.namespaceSuffix...)
 		}
 
 		e.setFieldByType(v.Field(f.idx), namespace, idx, f.isOmitEmpty)
+		e.embedded = false
 	}
+
+	e.path = e.path[:pl]
+	e.field, e.structType = field, structType
 }
@@ -1,8 +1,17 @@
 package form
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1351,7 +1360,16 @@ func TestEncoderErrors(t *testing.T) {
 	assert.Equal(t, k.Error(), "Bad Type Conversion")
 
 	k = ee["Struct"]
-	assert.Equal(t, k.Error(), "Unsupported Map Key '<struct {} Value>' Namespace 'Struct'")
+	assert.Equal(t, k.Error(), "Unsupported Map Key Value '{}' Type 'struct {}' Namespace 'Struct'")
+	assert.Equal(t, errors.Is(errs, ErrUnsupportedMapKey), true)
+	assert.Equal(t, e, `Field Namespace:BadMapKey ERROR:Bad Type Conversion
+Field Namespace:Struct ERROR:Unsupported Map Key Value '{}' Type 'struct {}' Namespace 'Struct'
+Field Namespace:Time ERROR:Bad Type Conversion`)
+
+	var fe *FieldError
+	assert.Equal(t, errors.As(k, &fe), true)
+	assert.Equal(t, fe.Field, "Struct")
+	assert.Equal(t, fe.Kind, ErrUnsupportedMapKey)
 }
 
 func TestEncoderPanicsAndBadValues(t *testing.T) {
@@ -1522,3 +1540,1000 @@ func TestOmitEmpty(t *testing.T) {
 	assert.Equal(t, values["x"][0], "0")
 	assert.Equal(t, values["arr[0]"][0], "")
 }
+
+func FuzzEncode(f *testing.F) {
+	type Nested struct {
+		Name  string
+		Value *int
+	}
+
+	type Test struct {
+		String    string
+		Int       int
+		Float     float32
+		Bool      bool
+		Time      time.Time
+		Iface     interface{}
+		Slice     []int
+		Array     [3]string
+		Nested    Nested
+		NestedPtr *Nested `form:",omitempty"`
+		Structs   []Nested
+		Map       map[string]int
+		IntMap    map[int][]string
+		MapMap    map[string]map[int]Nested
+		Matrix    [][2]int
+	}
+
+	seeds := []string{
+		"String=a&Int=1&Float=1.5&Bool=true&Time=2016-01-02T15:04:05Z&Iface=x",
+		"Slice=1&Slice=2&Slice[3]=4&Array[1]=b&Array=a",
+		"Nested.Name=a&NestedPtr.Value=2&Structs[1].Name=b&Structs[0].Value=1",
+		"Map[a]=1&IntMap[1][0]=a&MapMap[a][1].Name=b&Matrix[0][1]=2",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	decoder := NewDecoder()
+	decoder.SetMaxArraySize(100)
+	encoder := NewEncoder()
+
+	f.Fuzz(func(t *testing.T, query string) {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+
+		var test Test
+		_ = decoder.Decode(&test, values)
+		_, _ = encoder.Encode(test)
+		_, _ = encoder.Encode(&test)
+
+		var m map[string][]string
+		_ = decoder.Decode(&m, values)
+		_, _ = encoder.Encode(m)
+	})
+}
+
+type textKey struct {
+	a, b string
+}
+
+func (k *textKey) MarshalText() ([]byte, error) {
+	if k.a == "" {
+		return nil, errors.New("empty key")
+	}
+
+	return []byte(k.a + "-" + k.b), nil
+}
+
+func TestEncoderTextMarshaler(t *testing.T) {
+	type Test struct {
+		Addr    netip.Addr
+		AddrPtr *netip.Addr
+		Addrs   []netip.Addr
+		IP      net.IP
+		ID      textID
+		IDs     []*textID
+		IDMap   map[textID]textID
+		KeyMap  map[textKey]int
+		Big     *big.Int
+		Nil     *big.Int
+		Key     textKey
+		Custom  textID
+	}
+
+	id := textID(3)
+	addr := netip.MustParseAddr("::1")
+	big, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	test := Test{
+		Addr:    netip.MustParseAddr("127.0.0.1"),
+		AddrPtr: &addr,
+		Addrs:   []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")},
+		IP:      net.ParseIP("192.168.0.1"),
+		ID:      1,
+		IDs:     []*textID{&id},
+		IDMap:   map[textID]textID{5: 6},
+		KeyMap:  map[textKey]int{{a: "a", b: "b"}: 1},
+		Big:     big,
+		Key:     textKey{a: "c", b: "d"},
+		Custom:  7,
+	}
+
+	encoder := NewEncoder()
+	encoder.RegisterCustomTypeFunc(func(x interface{}) ([]string, error) {
+		return []string{strconv.Itoa(int(x.(textID)) * 10)}, nil
+	}, textID(0))
+
+	values, err := encoder.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"Addr":        []string{"127.0.0.1"},
+		"AddrPtr":     []string{"::1"},
+		"Addrs":       []string{"10.0.0.1", "10.0.0.2"},
+		"IP":          []string{"192.168.0.1"},
+		"ID":          []string{"10"},
+		"IDs[0]":      []string{"30"},
+		"IDMap[50]":   []string{"60"},
+		"KeyMap[a-b]": []string{"1"},
+		"Big":         []string{"123456789012345678901234567890"},
+		"Key":         []string{"c-d"},
+		"Custom":      []string{"70"},
+	})
+
+	encoder = NewEncoder()
+	values, err = encoder.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["ID"], []string{"id-1"})
+	assert.Equal(t, values["IDs[0]"], []string{"id-3"})
+	assert.Equal(t, values["IDMap[id-5]"], []string{"id-6"})
+
+	// round trip, textKey only implements encoding.TextMarshaler
+	delete(values, "Key")
+	delete(values, "KeyMap[a-b]")
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.Addr, test.Addr)
+	assert.Equal(t, *decoded.AddrPtr, addr)
+	assert.Equal(t, decoded.Addrs, test.Addrs)
+	assert.Equal(t, decoded.IP.Equal(test.IP), true)
+	assert.Equal(t, decoded.IDs, test.IDs)
+	assert.Equal(t, decoded.IDMap, test.IDMap)
+	assert.Equal(t, decoded.Big.Cmp(big), 0)
+
+	test.Key = textKey{}
+	test.KeyMap = map[textKey]int{{}: 1}
+	_, err = encoder.Encode(test)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Key ERROR:empty key\nField Namespace:KeyMap ERROR:empty key")
+}
+
+func TestEncoderMarshaler(t *testing.T) {
+	type Test struct {
+		Price  money
+		Total  *money
+		Nil    *money
+		Prices []money
+		ByKey  map[string]money
+	}
+
+	test := Test{
+		Price:  money{Amount: 100, Currency: "EUR"},
+		Total:  &money{Amount: 200, Currency: "USD"},
+		Prices: []money{{Amount: 1, Currency: "GBP"}},
+		ByKey:  map[string]money{"a": {Amount: 2, Currency: "JPY"}},
+	}
+
+	encoder := NewEncoder()
+	values, err := encoder.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"Price.Amount":       []string{"100"},
+		"Price.Currency":     []string{"EUR"},
+		"Total.Amount":       []string{"200"},
+		"Total.Currency":     []string{"USD"},
+		"Prices[0].Amount":   []string{"1"},
+		"Prices[0].Currency": []string{"GBP"},
+		"ByKey[a].Amount":    []string{"2"},
+		"ByKey[a].Currency":  []string{"JPY"},
+	})
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+
+	encoder.SetNamespacePrefix("[")
+	encoder.SetNamespaceSuffix("]")
+	values, err = encoder.Encode(Test{Price: money{Amount: 3, Currency: "SEK"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{"Price[Amount]": []string{"3"}, "Price[Currency]": []string{"SEK"}})
+
+	values, err = encoder.Encode(money{Amount: 4, Currency: "NOK"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{"Amount": []string{"4"}, "Currency": []string{"NOK"}})
+
+	_, err = encoder.Encode(Test{})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.Error(), "Field Namespace:Price ERROR:missing currency")
+}
+
+func TestEncoderTyped(t *testing.T) {
+	type customString string
+
+	type Test struct {
+		Name  customString
+		Slice []customString
+		Map   map[customString]customString
+		Ptr   *customString
+		Bad   customString
+	}
+
+	ptr := customString("ptr")
+	test := Test{
+		Name:  "joeybloggs",
+		Slice: []customString{"v1", "v2"},
+		Map:   map[customString]customString{"key": "value"},
+		Ptr:   &ptr,
+		Bad:   "bad",
+	}
+
+	e := NewEncoder()
+	RegisterEncoder(e, func(s customString) (string, error) {
+		if s == "bad" {
+			return "", errors.New("bad value")
+		}
+		return "custom" + string(s), nil
+	})
+
+	values, err := e.Encode(test)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(EncodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["Bad"].Error(), "bad value")
+	assert.Equal(t, values["Name"], []string{"customjoeybloggs"})
+	assert.Equal(t, values["Slice[0]"], []string{"customv1"})
+	assert.Equal(t, values["Slice[1]"], []string{"customv2"})
+	assert.Equal(t, values["Map[customkey]"], []string{"customvalue"})
+	assert.Equal(t, values["Ptr"], []string{"customptr"})
+
+	// addressable values
+	values, err = e.Encode(&test)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, values["Name"], []string{"customjoeybloggs"})
+}
+
+type hookedLine struct {
+	Price    int `form:"price"`
+	Quantity int `form:"qty"`
+	Total    int `form:"total"`
+}
+
+func (l *hookedLine) BeforeEncode() error {
+	if l.Quantity < 0 {
+		return errors.New("negative quantity")
+	}
+
+	l.Total = l.Price * l.Quantity
+	return nil
+}
+
+type hookedInvoice struct {
+	Lines []hookedLine `form:"lines"`
+	Count int          `form:"count"`
+}
+
+func (i *hookedInvoice) BeforeEncode() error {
+	i.Count = len(i.Lines)
+	return nil
+}
+
+func TestEncoderHooks(t *testing.T) {
+	test := hookedInvoice{
+		Lines: []hookedLine{{Price: 2, Quantity: 3}, {Price: 4, Quantity: -1}},
+	}
+
+	e := NewEncoder()
+	values, err := e.Encode(test)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(EncodeErrors)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs["lines[1]"].Error(), "negative quantity")
+	assert.Equal(t, values["count"], []string{"2"})
+	assert.Equal(t, values["lines[0].total"], []string{"6"})
+	assert.Equal(t, values["lines[1].price"], nil)
+
+	// not addressable so changes are not kept
+	assert.Equal(t, test.Count, 0)
+
+	values, err = e.Encode(&test)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, values["count"], []string{"2"})
+	assert.Equal(t, test.Count, 2)
+	assert.Equal(t, test.Lines[0].Total, 6)
+}
+
+func TestEncoderContext(t *testing.T) {
+	type ctxKey struct{}
+
+	type Test struct {
+		Created time.Time   `form:"created,layout=2006-01-02"`
+		Updated time.Time   `form:"updated"`
+		Times   []time.Time `form:"times,layout=15:04"`
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, time.FixedZone("X", 3600))
+
+	var infos []FieldInfo
+	e := NewEncoder()
+	e.RegisterCustomTypeFuncContext(func(ctx context.Context, info FieldInfo, x interface{}) ([]string, error) {
+		infos = append(infos, info)
+		layout := time.RFC3339
+		for _, opt := range info.Options {
+			if strings.HasPrefix(opt, "layout=") {
+				layout = opt[len("layout="):]
+			}
+		}
+		return []string{x.(time.Time).In(ctx.Value(ctxKey{}).(*time.Location)).Format(layout)}, nil
+	}, time.Time{})
+
+	tm := time.Date(2020, 1, 2, 23, 30, 0, 0, time.UTC)
+	values, err := e.EncodeContext(ctx, Test{Created: tm, Updated: tm, Times: []time.Time{tm}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["created"], []string{"2020-01-03"})
+	assert.Equal(t, values["updated"], []string{"2020-01-03T00:30:00+01:00"})
+	assert.Equal(t, values["times[0]"], []string{"00:30"})
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Namespace < infos[j].Namespace })
+	assert.Equal(t, len(infos), 3)
+	assert.Equal(t, infos[0].Field.Name, "Created")
+	assert.Equal(t, infos[0].Options, []string{"layout=2006-01-02"})
+	assert.Equal(t, len(infos[0].Values), 0)
+}
+
+func TestEncoderTimeOptions(t *testing.T) {
+	type Test struct {
+		DOB       time.Time   `form:"dob,layout=2006-01-02"`
+		Fallback  []time.Time `form:"fallback,layout=2006-01-02|01/02/2006"`
+		Unix      time.Time   `form:"unix,unix"`
+		UnixMilli *time.Time  `form:"unixmilli,unixmilli"`
+		Default   time.Time   `form:"default"`
+	}
+
+	loc := time.FixedZone("X", 3600)
+	tm := time.Date(2026, 10, 18, 23, 30, 0, 123000000, time.UTC)
+	test := Test{DOB: tm, Fallback: []time.Time{tm}, Unix: tm, UnixMilli: &tm, Default: tm}
+
+	e := NewEncoder()
+	e.SetTimeLocation(loc)
+
+	values, err := e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["dob"], []string{"2026-10-19"})
+	assert.Equal(t, values["fallback[0]"], []string{"2026-10-19"})
+	assert.Equal(t, values["unix"], []string{strconv.FormatInt(tm.Unix(), 10)})
+	assert.Equal(t, values["unixmilli"], []string{strconv.FormatInt(tm.UnixMilli(), 10)})
+	assert.Equal(t, values["default"], []string{"2026-10-19T00:30:00+01:00"})
+
+	// round trip
+	d := NewDecoder()
+	d.SetTimeLocation(loc)
+
+	var decoded Test
+	err = d.Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.DOB, time.Date(2026, 10, 19, 0, 0, 0, 0, loc))
+	assert.Equal(t, decoded.Unix.Equal(tm.Truncate(time.Second)), true)
+	assert.Equal(t, decoded.UnixMilli.Equal(tm), true)
+	assert.Equal(t, decoded.Default.Equal(tm.Truncate(time.Second)), true)
+
+	e = NewEncoder()
+	e.SetTimeLayouts("2006-01-02 15:04")
+
+	values, err = e.Encode(struct{ Time time.Time }{tm})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Time"], []string{"2026-10-18 23:30"})
+}
+
+func TestEncoderDuration(t *testing.T) {
+	type Test struct {
+		Timeout  time.Duration
+		Ptr      *time.Duration
+		Map      map[time.Duration]time.Duration
+		Fraction time.Duration
+		Int      int64
+	}
+
+	dur := time.Hour + 5*time.Minute
+	test := Test{
+		Timeout:  30 * time.Second,
+		Ptr:      &dur,
+		Map:      map[time.Duration]time.Duration{time.Minute: 2 * time.Minute},
+		Fraction: 1500 * time.Millisecond,
+		Int:      30,
+	}
+
+	e := NewEncoder()
+	values, err := e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Timeout"], []string{"30s"})
+	assert.Equal(t, values["Ptr"], []string{"1h5m0s"})
+	assert.Equal(t, values["Map[1m0s]"], []string{"2m0s"})
+	assert.Equal(t, values["Fraction"], []string{"1.5s"})
+	assert.Equal(t, values["Int"], []string{"30"})
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+
+	e.SetDurationUnit(time.Second)
+	values, err = e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Timeout"], []string{"30"})
+	assert.Equal(t, values["Map[60]"], []string{"120"})
+	assert.Equal(t, values["Fraction"], []string{"1.5"})
+
+	d := NewDecoder()
+	d.SetDurationUnit(time.Second)
+
+	decoded = Test{}
+	err = d.Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+}
+
+func TestEncoderComplexAndBig(t *testing.T) {
+	type Test struct {
+		C64   complex64
+		C128  *complex128
+		CMap  map[complex128]complex64
+		Int   big.Int
+		Float *big.Float
+		Rat   big.Rat
+	}
+
+	c128 := 3 - 4.25i
+	test := Test{
+		C64:  1.5 + 2i,
+		C128: &c128,
+		CMap: map[complex128]complex64{1i: 2},
+	}
+	test.Int.SetString("123456789012345678901234567890", 10)
+	test.Rat.SetString("1/3")
+
+	// decoded floats have the precision of their digits, so encode back exactly
+	err := NewDecoder().Decode(&test, url.Values{"Float": []string{"12345678901234567890.123456789012345678901"}})
+	assert.Equal(t, err, nil)
+
+	values, err := NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["C64"], []string{"(1.5+2i)"})
+	assert.Equal(t, values["Float"], []string{"12345678901234567890.123456789012345678901"})
+	assert.Equal(t, values["C128"], []string{"(3-4.25i)"})
+	assert.Equal(t, values["CMap[(0+1i)]"], []string{"(2+0i)"})
+	assert.Equal(t, values["Int"], []string{"123456789012345678901234567890"})
+	assert.Equal(t, values["Rat"], []string{"1/3"})
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.C64, test.C64)
+	assert.Equal(t, *decoded.C128, c128)
+	assert.Equal(t, decoded.CMap, test.CMap)
+	assert.Equal(t, decoded.Int.Cmp(&test.Int), 0)
+	assert.Equal(t, decoded.Float.Cmp(test.Float), 0)
+	assert.Equal(t, decoded.Rat.Cmp(&test.Rat), 0)
+
+	// as the value passed to Encode
+	values, err = NewEncoder().Encode(test.Int)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values[""], []string{"123456789012345678901234567890"})
+
+	// floats built in the program are encoded with every digit their shortest digits lack
+	third := new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3))
+	floats := []*big.Float{
+		big.NewFloat(0.1),
+		big.NewFloat(1.5),
+		third,
+		new(big.Float).SetPrec(300).SetMantExp(big.NewFloat(1), 250),
+		big.NewFloat(-1e-20),
+		new(big.Float),
+	}
+	for _, f := range floats {
+		values, err = NewEncoder().Encode(Test{Float: f})
+		assert.Equal(t, err, nil)
+
+		decoded = Test{}
+		err = NewDecoder().Decode(&decoded, values)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decoded.Float.Cmp(f), 0)
+	}
+
+	values, err = NewEncoder().Encode(Test{Float: big.NewFloat(0.1)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Float"], []string{"0.1000000000000000055511151231257827021181583404541015625"})
+
+	values, err = NewEncoder().Encode(Test{Float: big.NewFloat(1.5)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Float"], []string{"1.5"})
+}
+
+func TestEncoderStdlibTypes(t *testing.T) {
+	type Test struct {
+		AddrPort netip.AddrPort
+		URL      *url.URL
+		URLs     []url.URL
+		Email    mail.Address
+		ID       [16]byte `form:"id,uuid"`
+		Hash     [4]byte  `form:"hash,hex"`
+		IDs      []*[16]byte
+		Bytes    [2]byte
+		IDMap    map[[4]byte]int
+	}
+
+	u, _ := url.Parse("https://example.com/path?q=1")
+	test := Test{
+		AddrPort: netip.MustParseAddrPort("127.0.0.1:8080"),
+		URL:      u,
+		URLs:     []url.URL{{Path: "/a"}, {Scheme: "mailto", Opaque: "joey@example.com"}},
+		Email:    mail.Address{Name: "Joey Bloggs", Address: "joey@example.com"},
+		ID:       [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+		Hash:     [4]byte{0xde, 0xad, 0xbe, 0xef},
+		IDs:      []*[16]byte{{15: 1}},
+		Bytes:    [2]byte{1, 2},
+		IDMap:    map[[4]byte]int{{10, 11, 12, 13}: 1},
+	}
+
+	e := NewEncoder()
+	e.SetByteArrayFormat(ByteArrayHex)
+
+	values, err := e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["AddrPort"], []string{"127.0.0.1:8080"})
+	assert.Equal(t, values["URL"], []string{"https://example.com/path?q=1"})
+	assert.Equal(t, values["URLs[0]"], []string{"/a"})
+	assert.Equal(t, values["URLs[1]"], []string{"mailto:joey@example.com"})
+	assert.Equal(t, values["Email"], []string{`"Joey Bloggs" <joey@example.com>`})
+	assert.Equal(t, values["id"], []string{"123e4567-e89b-12d3-a456-426614174000"})
+	assert.Equal(t, values["hash"], []string{"deadbeef"})
+	assert.Equal(t, values["IDs[0]"], []string{"00000000000000000000000000000001"})
+	assert.Equal(t, values["Bytes"], []string{"0102"})
+	assert.Equal(t, values["IDMap[0a0b0c0d]"], []string{"1"})
+
+	d := NewDecoder()
+	d.SetByteArrayFormat(ByteArrayHex)
+
+	var decoded Test
+	err = d.Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.AddrPort, test.AddrPort)
+	assert.Equal(t, *decoded.URL, *test.URL)
+	assert.Equal(t, decoded.Email, test.Email)
+	assert.Equal(t, decoded.ID, test.ID)
+	assert.Equal(t, *decoded.IDs[0], *test.IDs[0])
+	assert.Equal(t, decoded.Bytes, test.Bytes)
+	assert.Equal(t, decoded.IDMap, test.IDMap)
+
+	// byte arrays are encoded a value per element by default, and are not supported as map keys
+	test.IDMap = nil
+	values, err = NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Bytes"], []string{"1", "2"})
+	assert.Equal(t, values["hash"], []string{"deadbeef"})
+}
+
+func TestEncoderSQL(t *testing.T) {
+	type Test struct {
+		Name     sql.NullString
+		Age      sql.NullInt64
+		Score    *sql.NullFloat64
+		Born     sql.NullTime `form:"born,layout=2006-01-02"`
+		Count    sql.Null[int]
+		Missing  sql.NullString
+		Omitted  sql.NullString `form:",omitempty"`
+		Status   scannedStatus
+		Statuses []scannedStatus
+	}
+
+	test := Test{
+		Name:     sql.NullString{String: "joeybloggs", Valid: true},
+		Age:      sql.NullInt64{Int64: 3, Valid: true},
+		Score:    &sql.NullFloat64{Float64: 1.5, Valid: true},
+		Born:     sql.NullTime{Time: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), Valid: true},
+		Count:    sql.Null[int]{V: 7, Valid: true},
+		Status:   "unknown",
+		Statuses: []scannedStatus{"open", "closed"},
+	}
+
+	values, err := NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Name"], []string{"joeybloggs"})
+	assert.Equal(t, values["Age"], []string{"3"})
+	assert.Equal(t, values["Score"], []string{"1.5"})
+	assert.Equal(t, values["born"], []string{"2024-02-29"})
+	assert.Equal(t, values["Count"], []string{"7"})
+	assert.Equal(t, values["Missing"], []string{""})
+	_, ok := values["Omitted"]
+	assert.Equal(t, ok, false)
+	assert.Equal(t, values["Status"], []string{"unknown"})
+
+	e := NewEncoder()
+	e.SetSQLBridge(true)
+
+	values, err = e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Name"], []string{"joeybloggs"})
+	assert.Equal(t, values["Status"], []string{""})
+	assert.Equal(t, values["Statuses"], []string{"open", "closed"})
+
+	d := NewDecoder()
+	d.SetSQLBridge(true)
+
+	var decoded Test
+	err = d.Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+}
+
+func TestEncoderBytes(t *testing.T) {
+	type Test struct {
+		Raw   []byte
+		Ptr   *[]byte
+		Sig   []byte            `form:"sig,base64"`
+		Hex   []byte            `form:"hex,hex"`
+		Files [][]byte          `form:"files,base64"`
+		Map   map[string][]byte `form:"map,hex"`
+		Nil   []byte
+		Empty []byte `form:",omitempty"`
+	}
+
+	ptr := []byte("ptr")
+	test := Test{
+		Raw:   []byte("hi"),
+		Ptr:   &ptr,
+		Sig:   []byte("hi??>>"),
+		Hex:   []byte{0xde, 0xad, 0xbe, 0xef},
+		Files: [][]byte{[]byte("a"), []byte("b")},
+		Map:   map[string][]byte{"a": {1, 2}},
+	}
+
+	values, err := NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Raw"], []string{"hi"})
+	assert.Equal(t, values["Ptr"], []string{"ptr"})
+	assert.Equal(t, values["sig"], []string{"aGk/Pz4+"})
+	assert.Equal(t, values["hex"], []string{"deadbeef"})
+	assert.Equal(t, values["files"], []string{"YQ==", "Yg=="})
+	assert.Equal(t, values["map[a]"], []string{"0102"})
+	_, ok := values["Nil"]
+	assert.Equal(t, ok, false)
+	_, ok = values["Empty"]
+	assert.Equal(t, ok, false)
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+}
+
+func TestEncoderSplit(t *testing.T) {
+	type Test struct {
+		IDs   []int       `form:"ids,split=,"`
+		Tags  []string    `form:"tags,split=|"`
+		One   []int       `form:"one,split=,"`
+		Times []time.Time `form:"times,split=,,layout=2006-01-02"`
+		Names []string
+	}
+
+	test := Test{
+		IDs:   []int{1, 2, 3},
+		Tags:  []string{"a", "b"},
+		One:   []int{1},
+		Times: []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Names: []string{"a", "b"},
+	}
+
+	values, err := NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["ids"], []string{"1,2,3"})
+	assert.Equal(t, values["tags"], []string{"a|b"})
+	assert.Equal(t, values["one"], []string{"1"})
+	assert.Equal(t, values["times[0]"], []string{"2024-01-02"})
+	assert.Equal(t, values["Names"], []string{"a", "b"})
+
+	e := NewEncoder()
+	e.SetSplitDelimiter(",")
+
+	values, err = e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Names"], []string{"a,b"})
+	assert.Equal(t, values["tags"], []string{"a|b"})
+
+	d := NewDecoder()
+	d.SetSplitDelimiter(",")
+
+	var decoded Test
+	err = d.Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+
+	// elements containing the delimiter would be decoded as several elements
+	test.Tags = []string{"a", "b|c"}
+	test.Names = []string{"a,b"}
+	_, err = e.Encode(test)
+	assert.NotEqual(t, err, nil)
+
+	errs := err.(EncodeErrors)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errors.Is(errs["tags[1]"], ErrSplitDelimiter), true)
+	assert.Equal(t, errs["tags[1]"].(*FieldError).Field, "Tags[1]")
+	assert.Equal(t, errs["Names[0]"].Error(), "Value Contains Split Delimiter ',' Value 'a,b' Type 'string' Namespace 'Names[0]'")
+}
+
+func TestEncoderEmptyBrackets(t *testing.T) {
+	type Item struct {
+		Name string
+	}
+
+	type Test struct {
+		Tags  []string
+		IDs   [2]int
+		Ptrs  []*int
+		Items []Item
+		Split []int `form:"split,split=,"`
+		Empty []string
+	}
+
+	i := 1
+	test := Test{
+		Tags:  []string{"a", "b"},
+		IDs:   [2]int{1, 2},
+		Ptrs:  []*int{nil, &i},
+		Items: []Item{{Name: "first"}},
+		Split: []int{1, 2},
+		Empty: []string{},
+	}
+
+	e := NewEncoder()
+	e.SetEmptyBrackets(true)
+
+	values, err := e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"Tags[]":        []string{"a", "b"},
+		"IDs[]":         []string{"1", "2"},
+		"Ptrs[1]":       []string{"1"},
+		"Items[0].Name": []string{"first"},
+		"split":         []string{"1,2"},
+	})
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.Tags, test.Tags)
+	assert.Equal(t, decoded.IDs, test.IDs)
+	assert.Equal(t, *decoded.Ptrs[1], i)
+	assert.Equal(t, decoded.Items, test.Items)
+	assert.Equal(t, decoded.Split, test.Split)
+}
+
+func TestEncoderKeySyntax(t *testing.T) {
+	type Address struct {
+		Name  string
+		Zip   *int
+		Lines []string
+	}
+
+	type User struct {
+		Name      string
+		Addresses []Address
+		Meta      map[string]int
+		Matrix    [][]int
+	}
+
+	type Test struct {
+		User User `form:"user"`
+	}
+
+	zip := 12345
+	test := Test{User: User{
+		Name: "joeybloggs",
+		Addresses: []Address{
+			{Name: "home", Zip: &zip, Lines: []string{"1 Street", "Town"}},
+			{Name: "work", Lines: []string{"2 Road"}},
+		},
+		Meta:   map[string]int{"age": 3},
+		Matrix: [][]int{{1, 2}, {3}},
+	}}
+
+	tests := []struct {
+		syntax   KeySyntax
+		expected url.Values
+	}{
+		{
+			syntax: KeySyntaxMixed,
+			expected: url.Values{
+				"user.Name":                  []string{"joeybloggs"},
+				"user.Addresses[0].Name":     []string{"home"},
+				"user.Addresses[0].Zip":      []string{"12345"},
+				"user.Addresses[0].Lines[0]": []string{"1 Street"},
+				"user.Addresses[0].Lines[1]": []string{"Town"},
+				"user.Addresses[1].Name":     []string{"work"},
+				"user.Addresses[1].Lines[0]": []string{"2 Road"},
+				"user.Meta[age]":             []string{"3"},
+				"user.Matrix[0][0]":          []string{"1"},
+				"user.Matrix[0][1]":          []string{"2"},
+				"user.Matrix[1][0]":          []string{"3"},
+			},
+		},
+		{
+			syntax: KeySyntaxBracket,
+			expected: url.Values{
+				"user[Name]":                   []string{"joeybloggs"},
+				"user[Addresses][0][Name]":     []string{"home"},
+				"user[Addresses][0][Zip]":      []string{"12345"},
+				"user[Addresses][0][Lines][0]": []string{"1 Street"},
+				"user[Addresses][0][Lines][1]": []string{"Town"},
+				"user[Addresses][1][Name]":     []string{"work"},
+				"user[Addresses][1][Lines][0]": []string{"2 Road"},
+				"user[Meta][age]":              []string{"3"},
+				"user[Matrix][0][0]":           []string{"1"},
+				"user[Matrix][0][1]":           []string{"2"},
+				"user[Matrix][1][0]":           []string{"3"},
+			},
+		},
+		{
+			syntax: KeySyntaxDot,
+			expected: url.Values{
+				"user.Name":                []string{"joeybloggs"},
+				"user.Addresses.0.Name":    []string{"home"},
+				"user.Addresses.0.Zip":     []string{"12345"},
+				"user.Addresses.0.Lines.0": []string{"1 Street"},
+				"user.Addresses.0.Lines.1": []string{"Town"},
+				"user.Addresses.1.Name":    []string{"work"},
+				"user.Addresses.1.Lines.0": []string{"2 Road"},
+				"user.Meta.age":            []string{"3"},
+				"user.Matrix.0.0":          []string{"1"},
+				"user.Matrix.0.1":          []string{"2"},
+				"user.Matrix.1.0":          []string{"3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		e := NewEncoder()
+		e.SetKeySyntax(tt.syntax)
+
+		values, err := e.Encode(test)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, values, tt.expected)
+
+		d := NewDecoder()
+		d.SetKeySyntax(tt.syntax)
+
+		var decoded Test
+		err = d.Decode(&decoded, values)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decoded, test)
+	}
+}
+
+func TestEncoderKeyEscaping(t *testing.T) {
+	type Test struct {
+		Map    map[string]string
+		Nested map[string]map[string]int
+		Dotted string `form:"a.b"`
+	}
+
+	test := Test{
+		Map: map[string]string{
+			"a]b":     "1",
+			"c[d":     "2",
+			"e.f":     "3",
+			`g\h`:     "4",
+			"[]":      "5",
+			"plain":   "6",
+			`\`:       "7",
+			"i[0]":    "8",
+			"j.k[l]m": "9",
+			"":        "10",
+			`\0`:      "11",
+		},
+		Nested: map[string]map[string]int{"x.y": {"[z]": 1}, "": {"": 2}},
+		Dotted: "dotted",
+	}
+
+	e := NewEncoder()
+	e.SetKeyEscaping(true)
+
+	values, err := e.Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Map[a\\]b]"], []string{"1"})
+	assert.Equal(t, values[`Map[e\.f]`], []string{"3"})
+	assert.Equal(t, values[`Map[g\\h]`], []string{"4"})
+	assert.Equal(t, values[`Nested[x\.y][\[z\]]`], []string{"1"})
+	assert.Equal(t, values[`a\.b`], []string{"dotted"})
+	assert.Equal(t, values[`Map[\0]`], []string{"10"})
+	assert.Equal(t, values[`Map[\\0]`], []string{"11"})
+	assert.Equal(t, values[`Nested[\0][\0]`], []string{"2"})
+
+	for _, syntax := range []KeySyntax{KeySyntaxMixed, KeySyntaxBracket, KeySyntaxDot} {
+		e := NewEncoder()
+		e.SetKeyEscaping(true)
+		e.SetKeySyntax(syntax)
+
+		values, err := e.Encode(test)
+		assert.Equal(t, err, nil)
+
+		d := NewDecoder()
+		d.SetKeyEscaping(true)
+		d.SetKeySyntax(syntax)
+		d.SetStrict(true)
+
+		var decoded Test
+		err = d.Decode(&decoded, values)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decoded, test)
+	}
+
+	// without escaping the keys are split wrongly
+	values, err = NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+
+	var decoded Test
+	_ = NewDecoder().Decode(&decoded, values)
+	assert.NotEqual(t, decoded.Map, test.Map)
+}
+
+// stampedEvent declares its own text methods, overriding those of the embedded time.Time.
+type stampedEvent struct {
+	time.Time
+	Name string
+}
+
+func (e stampedEvent) MarshalText() ([]byte, error) {
+	return []byte(e.Name + "@" + e.Format(time.DateOnly)), nil
+}
+
+func (e *stampedEvent) UnmarshalText(text []byte) error {
+	name, date, _ := strings.Cut(string(text), "@")
+	t, err := time.Parse(time.DateOnly, date)
+	e.Name, e.Time = name, t
+	return err
+}
+
+func TestEncoderEmbeddedTextMarshaler(t *testing.T) {
+	type Event struct {
+		time.Time
+		Name string
+	}
+
+	type Host struct {
+		netip.Addr
+		Name string
+	}
+
+	type Test struct {
+		Event Event
+		Host  *Host
+	}
+
+	test := Test{
+		Event: Event{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Name: "launch"},
+		Host:  &Host{Addr: netip.MustParseAddr("10.0.0.1"), Name: "db"},
+	}
+
+	// the methods promoted from the embedded field do not encode the whole struct
+	values, err := NewEncoder().Encode(test)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["Event"], []string{"2024-01-02T03:04:05Z"})
+	assert.Equal(t, values["Event.Name"], []string{"launch"})
+	assert.Equal(t, values["Host"], []string{"10.0.0.1"})
+	assert.Equal(t, values["Host.Name"], []string{"db"})
+
+	var decoded Test
+	err = NewDecoder().Decode(&decoded, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, test)
+
+	values, err = NewEncoder().Encode(test.Event)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{"": []string{"2024-01-02T03:04:05Z"}, "Name": []string{"launch"}})
+
+	// methods declared by the struct itself encode it whole
+	stamped := stampedEvent{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Name: "launch"}
+	values, err = NewEncoder().Encode(struct{ Event stampedEvent }{stamped})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{"Event": []string{"launch@2024-01-02"}})
+
+	var decodedStamped struct{ Event stampedEvent }
+	err = NewDecoder().Decode(&decodedStamped, values)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decodedStamped.Event, stamped)
+}
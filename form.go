@@ -1,7 +1,15 @@
 package form
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"math/big"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -25,10 +33,166 @@ const (
 	AnonymousSeparate
 )
 
-var timeType = reflect.TypeOf(time.Time{})
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	bigFloatType        = reflect.TypeOf(big.Float{})
+	urlType             = reflect.TypeOf(url.URL{})
+	mailAddressType     = reflect.TypeOf(mail.Address{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	validatorType       = reflect.TypeOf((*Validator)(nil)).Elem()
+	beforeDecoderType   = reflect.TypeOf((*BeforeDecoder)(nil)).Elem()
+	afterDecoderType    = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+	beforeEncoderType   = reflect.TypeOf((*BeforeEncoder)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
 
 // Mode specifies which mode the form decoder is to run.
 type Mode uint8
 
 // AnonymousMode specifies how data should be rolled up or separated from anonymous structs.
 type AnonymousMode uint8
+
+// KeySyntax specifies how the keys of nested values, struct fields, slice and array indexes and map keys, are written.
+type KeySyntax uint8
+
+const (
+	// KeySyntaxMixed separates struct fields with the namespace prefix and suffix, '.' by default,
+	// and puts indexes and map keys in brackets eg. User.Addresses[0].Name and Map[key].
+	KeySyntaxMixed KeySyntax = iota
+
+	// KeySyntaxBracket puts struct fields, indexes and map keys in brackets, as Rails does,
+	// eg. User[Addresses][0][Name] and Map[key].
+	KeySyntaxBracket
+
+	// KeySyntaxDot separates struct fields, indexes and map keys with '.', as gorilla/schema and Spring do,
+	// eg. User.Addresses.0.Name and Map.key; map keys must not contain '.'.
+	KeySyntaxDot
+)
+
+// affixes returns the namespace prefix and suffix of nested struct fields.
+func (k KeySyntax) affixes() (prefix, suffix string) {
+	if k == KeySyntaxBracket {
+		return "[", "]"
+	}
+
+	return ".", ""
+}
+
+// appendIndex appends a slice or array index to the namespace eg. [0] or .0
+func (k KeySyntax) appendIndex(namespace []byte, i int) []byte {
+	if k == KeySyntaxDot {
+		return strconv.AppendInt(append(namespace, '.'), int64(i), 10)
+	}
+
+	return append(strconv.AppendInt(append(namespace, '['), int64(i), 10), ']')
+}
+
+// appendKey appends a map key to the namespace eg. [key] or .key
+func (k KeySyntax) appendKey(namespace []byte, key string) []byte {
+	if k == KeySyntaxDot {
+		return append(append(namespace, '.'), key...)
+	}
+
+	return append(append(append(namespace, '['), key...), ']')
+}
+
+// ByteArrayFormat specifies how byte arrays eg. [16]byte are decoded and encoded.
+type ByteArrayFormat uint8
+
+const (
+	// ByteArrayElements decodes and encodes byte arrays as any other array, a value per element.
+	ByteArrayElements ByteArrayFormat = iota
+
+	// ByteArrayHex decodes and encodes byte arrays as a single hex value,
+	// 16 byte arrays are also decoded from canonical UUID text eg. 123e4567-e89b-12d3-a456-426614174000.
+	ByteArrayHex
+
+	// ByteArrayUUID decodes byte arrays as ByteArrayHex and encodes 16 byte arrays as canonical UUID text.
+	ByteArrayUUID
+)
+
+// FieldInfo describes the field a context-aware custom type function is called for.
+type FieldInfo struct {
+	// Field is the struct field, it is the zero value when not within a struct eg. the value passed to Decode
+	Field reflect.StructField
+	// Options are the tag options following the name eg. ["omitempty", "layout=2006-01-02"]
+	Options []string
+	// Namespace is the form namespace of the value eg. Address[0].Phone
+	Namespace string
+	// Values are the raw values being decoded, they are empty when encoding
+	Values []string
+}
+
+// FieldError describes an error encountered while decoding or encoding a single field.
+//
+// Kind holds the sentinel error describing the kind of failure, eg. ErrInvalidInteger,
+// and Err the underlying cause, eg. a *strconv.NumError; both are matched by errors.Is and errors.As.
+type FieldError struct {
+	// Namespace is the form namespace of the field eg. Address[0].Phone
+	Namespace string
+	// Field is the Go struct field path of the field eg. Addresses[0].PhoneNumber
+	Field string
+	// Value is the raw input value, when decoding
+	Value string
+	// Type is the type being decoded into or encoded from
+	Type reflect.Type
+	// Kind is the sentinel error describing the kind of failure, it is nil for errors
+	// returned by custom type functions.
+	Kind error
+	// Param is the parameter of a failed tag constraint eg. 18 for min=18
+	Param string
+	// Err is the underlying cause, if any
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Kind == nil {
+		if e.Err != nil {
+			return e.Err.Error()
+		}
+
+		return "Invalid Value '" + e.Value + "' Namespace '" + e.Namespace + "'"
+	}
+
+	kind := e.Kind.Error()
+	if len(e.Param) > 0 {
+		kind += " '" + e.Param + "'"
+	}
+
+	if e.Type == nil {
+		return kind + " Namespace '" + e.Namespace + "'"
+	}
+
+	return kind + " Value '" + e.Value + "' Type '" + e.Type.String() + "' Namespace '" + e.Namespace + "'"
+}
+
+// Unwrap returns the kind and cause of the error.
+func (e *FieldError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+
+	return errs
+}
+
+// sortedKeys returns the keys of an error map in sorted order
+// so that aggregated error output is stable.
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -2,28 +2,180 @@ package form
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
+var (
+	// ErrMissingStartBracket is returned, wrapped in a KeyError, when a key contains a ']' without a matching '['.
+	ErrMissingStartBracket = errors.New("missing '[' bracket")
+	// ErrMissingEndBracket is returned, wrapped in a KeyError, when a key contains a '[' that is never closed.
+	ErrMissingEndBracket = errors.New("missing ']' bracket")
+	// ErrIndexOverflow is returned, wrapped in a KeyError, when a slice or array index does not fit into an int.
+	ErrIndexOverflow = errors.New("index overflows int")
+	// ErrNegativeIndex is returned, wrapped in a KeyError, when a slice or array index is negative.
+	ErrNegativeIndex = errors.New("negative index")
+	// ErrInvalidInteger is the Kind of a FieldError for a value that is not a valid signed integer.
+	ErrInvalidInteger = errors.New("Invalid Integer")
+	// ErrInvalidUnsignedInteger is the Kind of a FieldError for a value that is not a valid unsigned integer.
+	ErrInvalidUnsignedInteger = errors.New("Invalid Unsigned Integer")
+	// ErrInvalidFloat is the Kind of a FieldError for a value that is not a valid float.
+	ErrInvalidFloat = errors.New("Invalid Float")
+	// ErrInvalidComplex is the Kind of a FieldError for a value that is not a valid complex number.
+	ErrInvalidComplex = errors.New("Invalid Complex")
+	// ErrInvalidBool is the Kind of a FieldError for a value that is not a valid boolean.
+	ErrInvalidBool = errors.New("Invalid Boolean")
+	// ErrInvalidValue is the Kind of a FieldError for a value rejected by the type itself
+	// eg. by its encoding.TextUnmarshaler implementation.
+	ErrInvalidValue = errors.New("Invalid")
+	// ErrInvalidDuration is the Kind of a FieldError for a value that is not a valid time.Duration.
+	ErrInvalidDuration = errors.New("Invalid Duration")
+	// ErrInvalidTime is the Kind of a FieldError for a value that can not be parsed as a time.Time.
+	ErrInvalidTime = errors.New("Invalid Time")
+	// ErrUnsupportedMapKey is the Kind of a FieldError for a map key type that can not be decoded or encoded.
+	ErrUnsupportedMapKey = errors.New("Unsupported Map Key")
+	// ErrUnknownKey is the Kind of a FieldError for a key that did not map to any field in strict mode.
+	ErrUnknownKey = errors.New("Unknown Key")
+	// ErrRequired is the Kind of a FieldError for a field tagged required that had no value.
+	ErrRequired = errors.New("Missing Required Field")
+	// ErrMin is the Kind of a FieldError for a value, or length, below its min tag constraint.
+	ErrMin = errors.New("Less Than Minimum")
+	// ErrMax is the Kind of a FieldError for a value, or length, above its max tag constraint.
+	ErrMax = errors.New("Greater Than Maximum")
+	// ErrLen is the Kind of a FieldError for a length not equal to its len tag constraint.
+	ErrLen = errors.New("Invalid Length")
+	// ErrPattern is the Kind of a FieldError for a value not matching its pattern tag constraint.
+	ErrPattern = errors.New("Does Not Match Pattern")
+	// ErrOneOf is the Kind of a FieldError for a value not listed in its oneof tag constraint.
+	ErrOneOf = errors.New("Not One Of")
+	// ErrArrayOverflow is the Kind of a FieldError for values that do not fit into a fixed size array,
+	// returned when SetArrayOverflowError is enabled; the Param is the length of the array.
+	ErrArrayOverflow = errors.New("Array Overflow, values exceed array length")
+	// ErrBigFloatPrecision is returned, wrapped in a FieldError of Kind ErrInvalidValue, for a big.Float value
+	// with more digits than its maximum precision of 65536 bits, about 19700 decimal digits, holds.
+	ErrBigFloatPrecision = errors.New("big.Float precision exceeds maximum")
+)
+
+var (
+	// ErrTooManyKeys is returned, wrapped in a LimitError, when the url.Values contain more keys than Limits.MaxKeys.
+	ErrTooManyKeys = errors.New("too many keys")
+	// ErrTooManyValues is returned, wrapped in a LimitError, when a key has more values than Limits.MaxValuesPerKey.
+	ErrTooManyValues = errors.New("too many values")
+	// ErrKeyTooLong is returned, wrapped in a LimitError, when a key is longer than Limits.MaxKeyLength.
+	ErrKeyTooLong = errors.New("key too long")
+	// ErrMaxDepth is returned, wrapped in a LimitError, when a key is nested deeper than Limits.MaxDepth.
+	ErrMaxDepth = errors.New("maximum depth exceeded")
+	// ErrTooManyMapEntries is returned, wrapped in a LimitError, when a map would hold more than Limits.MaxMapEntries.
+	ErrTooManyMapEntries = errors.New("too many map entries")
+	// ErrTooManyElements is returned, wrapped in a LimitError,
+	// when decoding would allocate more slice and map elements than Limits.MaxElements.
+	ErrTooManyElements = errors.New("too many elements")
+)
+
+// Limits restricts the work and memory a single Decode call may use on untrusted input.
+// A zero value for any of the limits means it is not enforced.
+//
+// MaxKeys, MaxValuesPerKey, MaxKeyLength and MaxDepth are checked before any decoding takes place
+// and cause Decode to return a *LimitError; MaxMapEntries and MaxElements are checked while decoding
+// and are reported within DecodeErrors under the namespace of the offending field.
+type Limits struct {
+	// MaxKeys is the maximum number of keys in the url.Values.
+	MaxKeys int
+	// MaxValuesPerKey is the maximum number of values of a single key.
+	MaxValuesPerKey int
+	// MaxKeyLength is the maximum length of a single key.
+	MaxKeyLength int
+	// MaxDepth is the maximum number of namespace segments in a key eg. "a[b].c" has a depth of 3.
+	MaxDepth int
+	// MaxMapEntries is the maximum number of entries of a single map.
+	MaxMapEntries int
+	// MaxElements is the maximum number of slice and map elements allocated in total.
+	MaxElements int
+}
+
+// LimitError describes a breach of the Limits set on the Decoder.
+type LimitError struct {
+	// Key is the offending key or namespace, it is blank for limits on the url.Values as a whole.
+	Key   string
+	Limit int
+	Err   error
+}
+
+func (e *LimitError) Error() string {
+	if e.Key == blank {
+		return "Limit Exceeded, " + e.Err.Error() + " limit '" + strconv.Itoa(e.Limit) + "'"
+	}
+
+	return "Limit Exceeded, " + e.Err.Error() + " limit '" + strconv.Itoa(e.Limit) + "' Key '" + e.Key + "'"
+}
+
+// Unwrap returns the sentinel error of the limit that was exceeded.
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// WarningKind specifies the kind of problem a DecodeWarning describes.
+type WarningKind uint8
+
+const (
+	// WarningArrayOverflow is emitted when more values, or a higher index,
+	// are passed than a fixed size array can hold; the overflow values are ignored.
+	WarningArrayOverflow WarningKind = iota
+)
+
+func (k WarningKind) String() string {
+	switch k {
+	case WarningArrayOverflow:
+		return "array overflow"
+	default:
+		return "unknown warning"
+	}
+}
+
+// DecodeWarning describes values that were dropped during decoding without causing an error.
+type DecodeWarning struct {
+	Namespace string
+	Kind      WarningKind
+	Values    []string
+}
+
+// WarningFunc is called for every DecodeWarning encountered during decoding.
+type WarningFunc func(w DecodeWarning)
+
 // DecodeErrors is a map of errors encountered during form decoding
 type DecodeErrors map[string]error
 
 func (d DecodeErrors) Error() string {
 	buff := bytes.NewBufferString(blank)
-	for k, err := range d {
+	for _, k := range sortedKeys(d) {
 		buff.WriteString(fieldNS)
 		buff.WriteString(k)
 		buff.WriteString(errorText)
-		buff.WriteString(err.Error())
+		buff.WriteString(d[k].Error())
 		buff.WriteString("\n")
 	}
 
 	return strings.TrimSpace(buff.String())
 }
 
+// Unwrap returns the contained errors sorted by namespace,
+// allowing errors.Is and errors.As to match any of them.
+func (d DecodeErrors) Unwrap() []error {
+	errs := make([]error, 0, len(d))
+	for _, k := range sortedKeys(d) {
+		errs = append(errs, d[k])
+	}
+
+	return errs
+}
+
 // InvalidDecoderError describes an invalid argument passed to Decode.
 // Argument passed to Decode must be a non-nil pointer.
 type InvalidDecoderError struct {
@@ -42,19 +194,95 @@ func (e *InvalidDecoderError) Error() string {
 	return "form: Decode(nil " + e.Type.String() + ")"
 }
 
+// KeyError describes a malformed url.Values key.
+// It is reported within DecodeErrors instead of the key being decoded.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return "Invalid formatting for key '" + e.Key + "' " + e.Err.Error()
+}
+
+// Unwrap returns the underlying reason the key is malformed.
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshaler is implemented by types that decode their whole sub-tree of values themselves,
+// eg. a Money type reading both "Price.Amount" and "Price.Currency".
+//
+// UnmarshalForm is passed every value whose key is within the type's namespace,
+// with the namespace removed so keys look as they would at the root;
+// eg. "Price.Amount" is passed as "Amount", "Price[0]" as "[0]"
+// and a value at exactly "Price" under a blank key.
+// It is only called when at least one such value exists and registered custom type functions take priority.
+type Unmarshaler interface {
+	UnmarshalForm(values url.Values) error
+}
+
+// Validator is implemented by structs that check themselves once decoded,
+// Validate is called for the value passed to Decode and every nested struct that was set.
+//
+// A *FieldError returned, or joined within the error, has its Field, the Go path
+// relative to the struct eg. "Addresses[0].Phone", rewritten to the form namespace
+// the decoder uses eg. "Address[0].Phone"; any other error is set for the struct itself.
+// Embedded structs are validated through the struct embedding them.
+type Validator interface {
+	Validate() error
+}
+
+// BeforeDecoder is implemented by structs that prepare themselves before their fields are decoded,
+// BeforeDecode is passed a copy of the values within the struct's namespace relative to it, as for an Unmarshaler,
+// and the fields are decoded from the values as it leaves them, so it may normalise, add or remove values.
+// Embedded structs are called through the struct embedding them.
+type BeforeDecoder interface {
+	BeforeDecode(values url.Values)
+}
+
+// AfterDecoder is implemented by structs that complete themselves once their fields are decoded,
+// eg. computing derived fields; it is called for every struct that was set, before Validate,
+// and errors are recorded just as those returned by Validate.
+// Embedded structs are called through the struct embedding them.
+type AfterDecoder interface {
+	AfterDecode() error
+}
+
 // DecodeCustomTypeFunc allows for registering/overriding types to be parsed.
 type DecodeCustomTypeFunc func([]string) (interface{}, error)
 
+// decodeFunc sets v from the values, it is what both typed and untyped custom type functions are stored as.
+type decodeFunc func(d *decoder, v reflect.Value, namespace []byte, vals []string) error
+
+// DecodeCustomTypeFuncContext allows for registering/overriding types to be parsed
+// with the context passed to DecodeContext and the field being decoded.
+type DecodeCustomTypeFuncContext func(ctx context.Context, info FieldInfo) (interface{}, error)
+
 // Decoder is the main decode instance
 type Decoder struct {
-	mode            Mode
-	tagName         string
-	dataPool        *sync.Pool
-	structCache     *structCacheMap
-	maxArraySize    int
-	namespacePrefix string
-	namespaceSuffix string
-	customTypeFuncs map[reflect.Type]DecodeCustomTypeFunc
+	mode               Mode
+	strict             bool
+	limits             Limits
+	tagName            string
+	dataPool           *sync.Pool
+	structCache        *structCacheMap
+	warningFunc        WarningFunc
+	maxArraySize       int
+	namespacePrefix    string
+	namespaceSuffix    string
+	customTypeFuncs    map[reflect.Type]decodeFunc
+	arrayOverflowError bool
+	timeLayouts        []string
+	timeLocation       *time.Location
+	durationUnit       time.Duration
+	byteArrayFormat    ByteArrayFormat
+	sqlBridge          bool
+	splitDelimiter     string
+	keySyntax          KeySyntax
+	escapeKeys         bool
+	embedAnonymous     bool
+	separateAnonymous  bool
 }
 
 // NewDecoder creates a new decoder instance with sane defaults
@@ -65,8 +293,15 @@ func NewDecoder() *Decoder {
 		structCache:     newStructCacheMap(),
 		maxArraySize:    10000,
 		namespacePrefix: ".",
+		timeLayouts:     []string{time.RFC3339},
+		durationUnit:    time.Nanosecond,
+		// embedded structs are decoded both flattened and under their type name until SetAnonymousMode is called
+		embedAnonymous:    true,
+		separateAnonymous: true,
 	}
 
+	d.structCache.defaultFn = d.checkDefault
+
 	d.dataPool = &sync.Pool{New: func() interface{} {
 		return &decoder{
 			d:         d,
@@ -82,6 +317,18 @@ func (d *Decoder) SetMode(mode Mode) {
 	d.mode = mode
 }
 
+// SetAnonymousMode sets how the decoder fills anonymous structs, matching the Encoder with the same mode;
+// AnonymousEmbed decodes them from the flattened keys only, a key shared by fields of the same name giving each
+// of them its next value in the order the Encoder appends them, and AnonymousSeparate decodes them from the keys
+// under their type name only. The values of a shared key can only be told apart for fields decoded from a single value,
+// other fields after the first are left unset.
+//
+// Default, when not set, decodes them from both the flattened keys and the keys under their type name.
+func (d *Decoder) SetAnonymousMode(mode AnonymousMode) {
+	d.embedAnonymous = mode == AnonymousEmbed
+	d.separateAnonymous = mode == AnonymousSeparate
+}
+
 // SetTagName sets the given tag name to be used by the decoder.
 //
 // Default is "form".
@@ -89,6 +336,69 @@ func (d *Decoder) SetTagName(tagName string) {
 	d.tagName = tagName
 }
 
+// SetTimeLayouts sets the layouts tried in order when decoding a time.Time,
+// for fields without a layout tag option eg. `form:"dob,layout=2006-01-02"`.
+//
+// Default is time.RFC3339.
+func (d *Decoder) SetTimeLayouts(layouts ...string) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+
+	d.timeLayouts = layouts
+}
+
+// SetTimeLocation sets the location of times decoded with a layout without a zone,
+// and of times decoded from unix timestamps.
+//
+// Default is UTC.
+func (d *Decoder) SetTimeLocation(loc *time.Location) {
+	d.timeLocation = loc
+}
+
+// SetDurationUnit sets the unit of plain numbers decoded into a time.Duration,
+// which otherwise uses time.ParseDuration syntax eg. "30s" or "5m"; a unit of 0 only accepts that syntax.
+//
+// Default is time.Nanosecond.
+func (d *Decoder) SetDurationUnit(unit time.Duration) {
+	d.durationUnit = unit
+}
+
+// SetByteArrayFormat sets how byte arrays eg. [16]byte are decoded,
+// for fields without a hex or uuid tag option.
+//
+// Default is ByteArrayElements.
+func (d *Decoder) SetByteArrayFormat(format ByteArrayFormat) {
+	d.byteArrayFormat = format
+}
+
+// SetSQLBridge sets whether types implementing sql.Scanner are decoded using their Scan method,
+// which is passed the value as a string or nil when it is empty.
+// The database/sql Null types are always decoded from a single value, empty meaning not Valid.
+//
+// Default is false.
+func (d *Decoder) SetSQLBridge(enabled bool) {
+	d.sqlBridge = enabled
+}
+
+// SetSplitDelimiter sets the delimiter values of slices are split on into elements eg. "," for ids=1,2,3,
+// for fields without a split tag option eg. `form:"ids,split=,"`; each element is converted on its own
+// and errors set under its index eg. ids[1]. Byte slices are never split.
+//
+// Default is blank, values are not split.
+func (d *Decoder) SetSplitDelimiter(delimiter string) {
+	d.splitDelimiter = delimiter
+}
+
+// SetStrict sets whether the decoder should report url.Values keys
+// that did not map to any field.
+// When enabled every unused key is added to the returned DecodeErrors under its own key.
+//
+// Default is false.
+func (d *Decoder) SetStrict(strict bool) {
+	d.strict = strict
+}
+
 // SetMaxArraySize sets maximum array size that can be created.
 // This limit is for the array indexing this library supports to
 // avoid potential DOS or man-in-the-middle attacks using an unusually high number.
@@ -98,6 +408,40 @@ func (d *Decoder) SetMaxArraySize(size uint) {
 	d.maxArraySize = int(size)
 }
 
+// SetLimits sets the limits enforced when decoding untrusted input,
+// they apply in addition to SetMaxArraySize.
+//
+// Default is no limits.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// SetArrayOverflowError sets whether values that do not fit into a fixed size array
+// should be reported as an error instead of a WarningArrayOverflow warning.
+//
+// Default is false.
+func (d *Decoder) SetArrayOverflowError(enabled bool) {
+	d.arrayOverflowError = enabled
+}
+
+// SetKeySyntax sets the syntax of the keys of nested values, see KeySyntax;
+// it sets the namespace prefix and suffix of struct fields, which may be changed afterwards.
+//
+// Default is KeySyntaxMixed.
+func (d *Decoder) SetKeySyntax(syntax KeySyntax) {
+	d.keySyntax = syntax
+	d.namespacePrefix, d.namespaceSuffix = syntax.affixes()
+}
+
+// SetKeyEscaping sets whether a backslash escapes the following character of map keys and field names,
+// so keys containing '.', '[', ']' or '\' written by an Encoder with key escaping enabled decode to the original key
+// eg. Map[a\]b] is the key "a]b", and \0 is the empty key.
+//
+// Default is false.
+func (d *Decoder) SetKeyEscaping(enabled bool) {
+	d.escapeKeys = enabled
+}
+
 // SetNamespacePrefix sets a struct namespace prefix.
 func (d *Decoder) SetNamespacePrefix(namespacePrefix string) {
 	d.namespacePrefix = namespacePrefix
@@ -111,31 +455,152 @@ func (d *Decoder) SetNamespaceSuffix(namespaceSuffix string) {
 // Decode parses the given values and sets the corresponding struct and/or type values.
 // Decode returns an InvalidDecoderError if interface passed is invalid.
 func (d *Decoder) Decode(v interface{}, values url.Values) (err error) {
+	return d.DecodeContext(context.Background(), v, values)
+}
+
+// DecodeContext decodes the given values, as Decode, passing ctx to custom type functions
+// registered with RegisterCustomTypeFuncContext.
+func (d *Decoder) DecodeContext(ctx context.Context, v interface{}, values url.Values) (err error) {
 	val := reflect.ValueOf(v)
 	if val.Kind() != reflect.Ptr || val.IsNil() {
 		return &InvalidDecoderError{reflect.TypeOf(v)}
 	}
 
-	dec := d.dataPool.Get().(*decoder)
-	dec.values = values
-	dec.dm = dec.dm[0:0]
+	if err = d.checkLimits(values); err != nil {
+		return
+	}
+
 	val = val.Elem()
 	typ := val.Type()
-	if val.Kind() == reflect.Struct && typ != timeType {
+	dec := d.dataPool.Get().(*decoder)
+	dec.reset(values, d.strict, typ)
+	dec.ctx = ctx
+
+	if val.Kind() == reflect.Struct && !valueStruct(typ) && !d.decodesWhole(typ) {
 		dec.traverseStruct(val, typ, dec.namespace[0:0])
 	} else {
 		dec.setFieldByType(val, dec.namespace[0:0], 0)
 	}
 
+	if d.strict {
+		dec.checkUnused()
+	}
+
 	if len(dec.errs) > 0 {
 		err = dec.errs
 		dec.errs = nil
 	}
 
+	dec.ctx = nil
+	d.dataPool.Put(dec)
+	return
+}
+
+// checkDefault checks the default values from a struct tag convert to the type.
+func (d *Decoder) checkDefault(structType reflect.Type, f *cachedField, typ reflect.Type, vals []string) (err error) {
+	if !d.defaultSupported(typ) {
+		return fmt.Errorf("defaults are not supported for type '%v'", typ)
+	}
+
+	dec := d.dataPool.Get().(*decoder)
+	dec.reset(url.Values{"": vals}, false, nil)
+	dec.path = dec.path[:0]
+	dec.field, dec.structType = f, structType
+	dec.setFieldByType(reflect.New(typ).Elem(), dec.namespace[0:0], 0)
+	for _, e := range dec.errs {
+		err = e
+	}
+
+	dec.errs = nil
 	d.dataPool.Put(dec)
 	return
 }
 
+// defaultSupported reports whether a default value can be set for the type,
+// maps and structs without a custom type function or unmarshaler would need a value per field.
+func (d *Decoder) defaultSupported(typ reflect.Type) bool {
+	for {
+		if _, ok := d.customTypeFuncs[typ]; ok {
+			return true
+		}
+
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+			continue
+		case reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			return false
+		case reflect.Struct:
+			return valueStruct(typ) || d.decodesWhole(typ)
+		default:
+			return true
+		}
+	}
+}
+
+// decodesWhole reports whether a struct type is decoded as a whole rather than by its fields,
+// by a custom type function, Unmarshaler, encoding.TextUnmarshaler eg. big.Int, or sql.Scanner when bridged.
+func (d *Decoder) decodesWhole(typ reflect.Type) bool {
+	if _, ok := d.customTypeFuncs[typ]; ok {
+		return true
+	}
+
+	m := methodsOf(typ)
+	return m.pointer(methodUnmarshaler) || m.pointer(methodTextUnmarshaler) || (d.sqlBridge && m.pointer(methodScanner))
+}
+
+// checkLimits validates the shape of the values against the limits that can be checked ahead of decoding.
+func (d *Decoder) checkLimits(values url.Values) error {
+	l := d.limits
+	if l.MaxKeys > 0 && len(values) > l.MaxKeys {
+		return &LimitError{Limit: l.MaxKeys, Err: ErrTooManyKeys}
+	}
+
+	if l.MaxValuesPerKey <= 0 && l.MaxKeyLength <= 0 && l.MaxDepth <= 0 {
+		return nil
+	}
+
+	for k, vals := range values {
+		if l.MaxKeyLength > 0 && len(k) > l.MaxKeyLength {
+			return &LimitError{Key: k, Limit: l.MaxKeyLength, Err: ErrKeyTooLong}
+		}
+
+		if l.MaxValuesPerKey > 0 && len(vals) > l.MaxValuesPerKey {
+			return &LimitError{Key: k, Limit: l.MaxValuesPerKey, Err: ErrTooManyValues}
+		}
+
+		if l.MaxDepth > 0 && keyDepth(k, d.namespacePrefix) > l.MaxDepth {
+			return &LimitError{Key: k, Limit: l.MaxDepth, Err: ErrMaxDepth}
+		}
+	}
+
+	return nil
+}
+
+// keyDepth returns the number of namespace segments in the key,
+// counting each bracket pair and each struct namespace prefix outside of brackets.
+func keyDepth(k string, namespacePrefix string) int {
+	depth := 1
+	var insideBracket bool
+	for i := 0; i < len(k); i++ {
+		switch {
+		case k[i] == '[':
+			if !insideBracket {
+				depth++
+			}
+
+			insideBracket = true
+		case k[i] == ']':
+			insideBracket = false
+		case !insideBracket && len(namespacePrefix) > 0 && strings.HasPrefix(k[i:], namespacePrefix):
+			depth++
+			i += len(namespacePrefix) - 1
+		}
+	}
+
+	return depth
+}
+
 // RegisterCustomTypeFunc registers a CustomTypeFunc against a number of types.
 //
 // NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing.
@@ -143,15 +608,104 @@ func (d *Decoder) Decode(v interface{}, values url.Values) (err error) {
 // ADDITIONAL: if a struct type is registered,
 // the function will only be called if a url.Value exists for the struct and not just the struct fields eg.
 // url.Values{"User":"Name%3Djack"} will call the custom type function with `User` as the type,
-// however url.Values{"User.Name":"jack"} will not.
+// however url.Values{"User.Name":"jack"} will not; implement Unmarshaler to decode the struct fields together.
+//
+// The value returned must be assignable to the registered type, otherwise an error is recorded for the field;
+// use RegisterDecoder to have this checked at compile time.
 func (d *Decoder) RegisterCustomTypeFunc(fn DecodeCustomTypeFunc, types ...interface{}) {
-	if d.customTypeFuncs == nil {
-		d.customTypeFuncs = map[reflect.Type]DecodeCustomTypeFunc{}
+	set := func(_ *decoder, v reflect.Value, _ []byte, vals []string) error {
+		val, err := fn(vals)
+		if err != nil {
+			return err
+		}
+
+		return setCustomValue(v, val)
 	}
 
 	for _, t := range types {
-		d.customTypeFuncs[reflect.TypeOf(t)] = fn
+		d.registerFunc(reflect.TypeOf(t), set)
+	}
+}
+
+// RegisterCustomTypeFuncContext registers a DecodeCustomTypeFuncContext against a number of types,
+// it is passed the context given to DecodeContext, the struct field and tag options being decoded,
+// its namespace and values.
+//
+// NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing.
+func (d *Decoder) RegisterCustomTypeFuncContext(fn DecodeCustomTypeFuncContext, types ...interface{}) {
+	set := func(dec *decoder, v reflect.Value, namespace []byte, vals []string) error {
+		val, err := fn(dec.context(), dec.fieldInfo(namespace, vals))
+		if err != nil {
+			return err
+		}
+
+		return setCustomValue(v, val)
+	}
+
+	for _, t := range types {
+		d.registerFunc(reflect.TypeOf(t), set)
+	}
+}
+
+// setCustomValue sets the value returned by a custom type function.
+func setCustomValue(v reflect.Value, val interface{}) error {
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
 	}
+
+	rv := reflect.ValueOf(val)
+	if !rv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("custom type function returned type '%v' which is not assignable to type '%v'", rv.Type(), v.Type())
+	}
+
+	v.Set(rv)
+	return nil
+}
+
+func (d *Decoder) registerFunc(typ reflect.Type, fn decodeFunc) {
+	if d.customTypeFuncs == nil {
+		d.customTypeFuncs = map[reflect.Type]decodeFunc{}
+	}
+
+	d.customTypeFuncs[typ] = fn
+}
+
+// RegisterDecoder registers a typed custom decode function for T,
+// it is called with the first value for the field.
+//
+// NOTE: This function is not thread-safe it is intended that these all be registered prior to any parsing.
+func RegisterDecoder[T any](d *Decoder, fn func(string) (T, error)) {
+	d.registerFunc(reflect.TypeFor[T](), func(_ *decoder, v reflect.Value, _ []byte, vals []string) error {
+		t, err := fn(vals[0])
+		if err != nil {
+			return err
+		}
+
+		if v.CanAddr() {
+			*v.Addr().Interface().(*T) = t
+		} else {
+			v.Set(reflect.ValueOf(&t).Elem())
+		}
+
+		return nil
+	})
+}
+
+// DecodeAs decodes the values into a new T and returns it, T must be a struct, map or a type with
+// a registered custom type function, just as when calling Decode with a pointer.
+func DecodeAs[T any](d *Decoder, values url.Values) (T, error) {
+	var t T
+	err := d.Decode(&t, values)
+	return t, err
+}
+
+// RegisterWarningFunc registers a function that is called with every warning encountered during decoding,
+// by default warnings are discarded.
+//
+// NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing.
+func (d *Decoder) RegisterWarningFunc(fn WarningFunc) {
+	d.warningFunc = fn
 }
 
 // RegisterTagNameFunc registers a custom tag name parser function
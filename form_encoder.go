@@ -2,28 +2,46 @@ package form
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"net/url"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 )
 
+// ErrSplitDelimiter is the Kind of a FieldError for a slice element containing the delimiter the elements are joined with,
+// as it would be decoded as several elements; the Param is the delimiter.
+var ErrSplitDelimiter = errors.New("Value Contains Split Delimiter")
+
 // EncodeErrors is a map of errors encountered during form encoding.
 type EncodeErrors map[string]error
 
 func (e EncodeErrors) Error() string {
 	buff := bytes.NewBufferString(blank)
-	for k, err := range e {
+	for _, k := range sortedKeys(e) {
 		buff.WriteString(fieldNS)
 		buff.WriteString(k)
 		buff.WriteString(errorText)
-		buff.WriteString(err.Error())
+		buff.WriteString(e[k].Error())
 		buff.WriteString("\n")
 	}
 
 	return strings.TrimSpace(buff.String())
 }
 
+// Unwrap returns the contained errors sorted by namespace,
+// allowing errors.Is and errors.As to match any of them.
+func (e EncodeErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, k := range sortedKeys(e) {
+		errs = append(errs, e[k])
+	}
+
+	return errs
+}
+
 // InvalidEncodeError describes an invalid argument passed to Encode.
 type InvalidEncodeError struct {
 	Type reflect.Type
@@ -37,9 +55,34 @@ func (e *InvalidEncodeError) Error() string {
 	return "form: Encode(nil " + e.Type.String() + ")"
 }
 
+// Marshaler is implemented by types that encode their whole sub-tree of values themselves.
+//
+// MarshalForm returns keys relative to the type's namespace, in the same form as passed to Unmarshaler;
+// eg. for a field "Price" the key "Amount" is encoded as "Price.Amount", "[0]" as "Price[0]"
+// and a blank key as "Price".
+// Registered custom type functions take priority.
+type Marshaler interface {
+	MarshalForm() (url.Values, error)
+}
+
+// BeforeEncoder is implemented by structs that prepare themselves before their fields are encoded,
+// it is called on a copy when the struct is not addressable so changes are encoded but not kept.
+// When it returns an error the error is recorded under the struct's namespace and its fields are not encoded.
+// Embedded structs are called through the struct embedding them.
+type BeforeEncoder interface {
+	BeforeEncode() error
+}
+
 // EncodeCustomTypeFunc allows for registering/overriding types to be parsed.
 type EncodeCustomTypeFunc func(x interface{}) ([]string, error)
 
+// encodeFunc returns the values for v, it is what both typed and untyped custom type functions are stored as.
+type encodeFunc func(e *encoder, v reflect.Value, namespace []byte) ([]string, error)
+
+// EncodeCustomTypeFuncContext allows for registering/overriding types to be encoded
+// with the context passed to EncodeContext and the field being encoded.
+type EncodeCustomTypeFuncContext func(ctx context.Context, info FieldInfo, x interface{}) ([]string, error)
+
 // Encoder is the main encode instance.
 type Encoder struct {
 	mode            Mode
@@ -49,7 +92,16 @@ type Encoder struct {
 	embedAnonymous  bool
 	namespacePrefix string
 	namespaceSuffix string
-	customTypeFuncs map[reflect.Type]EncodeCustomTypeFunc
+	customTypeFuncs map[reflect.Type]encodeFunc
+	timeLayout      string
+	timeLocation    *time.Location
+	durationUnit    time.Duration
+	byteArrayFormat ByteArrayFormat
+	sqlBridge       bool
+	splitDelimiter  string
+	emptyBrackets   bool
+	keySyntax       KeySyntax
+	escapeKeys      bool
 }
 
 // NewEncoder creates a new encoder instance with sane defaults.
@@ -60,6 +112,7 @@ func NewEncoder() *Encoder {
 		structCache:     newStructCacheMap(),
 		embedAnonymous:  true,
 		namespacePrefix: ".",
+		timeLayout:      time.RFC3339,
 	}
 
 	e.dataPool = &sync.Pool{New: func() interface{} {
@@ -77,6 +130,24 @@ func (e *Encoder) SetAnonymousMode(mode AnonymousMode) {
 	e.embedAnonymous = mode == AnonymousEmbed
 }
 
+// SetKeySyntax sets the syntax of the keys of nested values, see KeySyntax;
+// it sets the namespace prefix and suffix of struct fields, which may be changed afterwards.
+//
+// Default is KeySyntaxMixed.
+func (e *Encoder) SetKeySyntax(syntax KeySyntax) {
+	e.keySyntax = syntax
+	e.namespacePrefix, e.namespaceSuffix = syntax.affixes()
+}
+
+// SetKeyEscaping sets whether '.', '[', ']' and '\' in map keys and field names are escaped with a backslash,
+// and the empty map key is written as \0, so any string map key decodes to the original key
+// by a Decoder with key escaping enabled eg. "a]b" as Map[a\]b] and "" as Map[\0].
+//
+// Default is false.
+func (e *Encoder) SetKeyEscaping(enabled bool) {
+	e.escapeKeys = enabled
+}
+
 // SetNamespacePrefix sets a struct namespace prefix.
 func (e *Encoder) SetNamespacePrefix(namespacePrefix string) {
 	e.namespacePrefix = namespacePrefix
@@ -87,6 +158,66 @@ func (e *Encoder) SetNamespaceSuffix(namespaceSuffix string) {
 	e.namespaceSuffix = namespaceSuffix
 }
 
+// SetTimeLayouts sets the layouts for time.Time, for fields without a layout tag option,
+// times are encoded with the first layout; it mirrors Decoder.SetTimeLayouts.
+//
+// Default is time.RFC3339.
+func (e *Encoder) SetTimeLayouts(layouts ...string) {
+	if len(layouts) == 0 {
+		e.timeLayout = time.RFC3339
+		return
+	}
+
+	e.timeLayout = layouts[0]
+}
+
+// SetTimeLocation sets the location times are converted to before being encoded.
+//
+// Default is nil, times are encoded in their own location.
+func (e *Encoder) SetTimeLocation(loc *time.Location) {
+	e.timeLocation = loc
+}
+
+// SetDurationUnit sets the unit time.Duration values are encoded in as plain numbers,
+// a unit of 0 encodes them using Duration.String eg. "1m30s"; it mirrors Decoder.SetDurationUnit.
+//
+// Default is 0.
+func (e *Encoder) SetDurationUnit(unit time.Duration) {
+	e.durationUnit = unit
+}
+
+// SetByteArrayFormat sets how byte arrays eg. [16]byte are encoded,
+// for fields without a hex or uuid tag option; it mirrors Decoder.SetByteArrayFormat.
+//
+// Default is ByteArrayElements.
+func (e *Encoder) SetByteArrayFormat(format ByteArrayFormat) {
+	e.byteArrayFormat = format
+}
+
+// SetSQLBridge sets whether types implementing driver.Valuer are encoded using their Value method,
+// a nil value being encoded as an empty value; it mirrors Decoder.SetSQLBridge.
+//
+// Default is false.
+func (e *Encoder) SetSQLBridge(enabled bool) {
+	e.sqlBridge = enabled
+}
+
+// SetSplitDelimiter sets the delimiter the elements of slices are joined with into a single value eg. "," for ids=1,2,3,
+// for fields without a split tag option; it mirrors Decoder.SetSplitDelimiter.
+//
+// Default is blank, elements are encoded as separate values.
+func (e *Encoder) SetSplitDelimiter(delimiter string) {
+	e.splitDelimiter = delimiter
+}
+
+// SetEmptyBrackets sets whether the repeated values of slices and arrays are encoded under their namespace
+// with empty brackets eg. Tags[]=a&Tags[]=b, as expected by PHP, Rack and jQuery; elements at an index are unchanged.
+//
+// Default is false.
+func (e *Encoder) SetEmptyBrackets(enabled bool) {
+	e.emptyBrackets = enabled
+}
+
 // SetTagName sets the given tag name to be used by the encoder.
 //
 // Default is "form"
@@ -105,13 +236,56 @@ func (e *Encoder) SetMode(mode Mode) {
 //
 // NOTE: this method is not thread-safe it is intended that these all be registered prior to any parsing.
 func (e *Encoder) RegisterCustomTypeFunc(fn EncodeCustomTypeFunc, types ...interface{}) {
-	if e.customTypeFuncs == nil {
-		e.customTypeFuncs = map[reflect.Type]EncodeCustomTypeFunc{}
+	get := func(_ *encoder, v reflect.Value, _ []byte) ([]string, error) {
+		return fn(v.Interface())
 	}
 
 	for _, t := range types {
-		e.customTypeFuncs[reflect.TypeOf(t)] = fn
+		e.registerFunc(reflect.TypeOf(t), get)
+	}
+}
+
+// RegisterCustomTypeFuncContext registers an EncodeCustomTypeFuncContext against a number of types,
+// it is passed the context given to EncodeContext, the struct field and tag options being encoded and its namespace.
+//
+// NOTE: this method is not thread-safe it is intended that these all be registered prior to any parsing.
+func (e *Encoder) RegisterCustomTypeFuncContext(fn EncodeCustomTypeFuncContext, types ...interface{}) {
+	get := func(enc *encoder, v reflect.Value, namespace []byte) ([]string, error) {
+		return fn(enc.context(), enc.fieldInfo(namespace), v.Interface())
+	}
+
+	for _, t := range types {
+		e.registerFunc(reflect.TypeOf(t), get)
+	}
+}
+
+func (e *Encoder) registerFunc(typ reflect.Type, fn encodeFunc) {
+	if e.customTypeFuncs == nil {
+		e.customTypeFuncs = map[reflect.Type]encodeFunc{}
 	}
+
+	e.customTypeFuncs[typ] = fn
+}
+
+// RegisterEncoder registers a typed custom encode function for T.
+//
+// NOTE: this function is not thread-safe it is intended that these all be registered prior to any parsing.
+func RegisterEncoder[T any](e *Encoder, fn func(T) (string, error)) {
+	e.registerFunc(reflect.TypeFor[T](), func(_ *encoder, v reflect.Value, _ []byte) ([]string, error) {
+		var t T
+		if v.CanAddr() {
+			t = *v.Addr().Interface().(*T)
+		} else {
+			t = v.Interface().(T)
+		}
+
+		s, err := fn(t)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{s}, nil
+	})
 }
 
 // RegisterTagNameFunc registers a custom tag name parser function.
@@ -127,6 +301,12 @@ func (e *Encoder) RegisterTagNameFunc(fn TagNameFunc) {
 
 // Encode encodes the given values and sets the corresponding struct values.
 func (e *Encoder) Encode(v interface{}) (values url.Values, err error) {
+	return e.EncodeContext(context.Background(), v)
+}
+
+// EncodeContext encodes the given value, as Encode, passing ctx to custom type functions
+// registered with RegisterCustomTypeFuncContext.
+func (e *Encoder) EncodeContext(ctx context.Context, v interface{}) (values url.Values, err error) {
 	val, kind := ExtractType(reflect.ValueOf(v))
 	if kind == reflect.Ptr || kind == reflect.Interface || kind == reflect.Invalid {
 		return nil, &InvalidEncodeError{reflect.TypeOf(v)}
@@ -134,7 +314,8 @@ func (e *Encoder) Encode(v interface{}) (values url.Values, err error) {
 
 	enc := e.dataPool.Get().(*encoder)
 	enc.values = make(url.Values)
-	if kind == reflect.Struct && val.Type() != timeType {
+	enc.ctx = ctx
+	if kind == reflect.Struct && !valueStruct(val.Type()) && !e.encodesWhole(val.Type()) {
 		enc.traverseStruct(val, enc.namespace[0:0], -1)
 	} else {
 		enc.setFieldByType(val, enc.namespace[0:0], -1, false)
@@ -146,6 +327,18 @@ func (e *Encoder) Encode(v interface{}) (values url.Values, err error) {
 	}
 
 	values = enc.values
+	enc.ctx = nil
 	e.dataPool.Put(enc)
 	return
 }
+
+// encodesWhole reports whether a struct type is encoded as a whole rather than by its fields,
+// by a custom type function, Marshaler, encoding.TextMarshaler eg. big.Int, or driver.Valuer when bridged.
+func (e *Encoder) encodesWhole(typ reflect.Type) bool {
+	if _, ok := e.customTypeFuncs[typ]; ok {
+		return true
+	}
+
+	m := methodsOf(typ)
+	return m.pointer(methodMarshaler) || m.pointer(methodTextMarshaler) || (e.sqlBridge && m.pointer(methodValuer))
+}
@@ -1,8 +1,20 @@
 package form
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"runtime"
 	"strconv"
+	"strings"
 )
 
 // ExtractType gets the actual underlying type of field value.
@@ -53,3 +65,418 @@ func hasValue(field reflect.Value) bool {
 		}
 	}
 }
+
+// formUnmarshaler returns the Unmarshaler of an addressable value with methods m, if implemented.
+func formUnmarshaler(v reflect.Value, m methods) (Unmarshaler, bool) {
+	if !m.pointer(methodUnmarshaler) || !v.CanAddr() {
+		return nil, false
+	}
+
+	return v.Addr().Interface().(Unmarshaler), true
+}
+
+// implementer returns the value with methods m, or a pointer to it when addressable, if it implements the interface.
+func implementer(v reflect.Value, m methods, i method) (interface{}, bool) {
+	if v.CanAddr() && m.pointer(i) {
+		return v.Addr().Interface(), true
+	}
+
+	if m.value(i) {
+		return v.Interface(), true
+	}
+
+	return nil, false
+}
+
+// addressable returns the value, or a pointer to it when only the pointer implements the interface,
+// copying values that are not addressable eg. map keys so pointer receiver methods can be called.
+func addressable(v reflect.Value, m methods, i method) interface{} {
+	if m.value(i) {
+		return v.Interface()
+	}
+
+	if !v.CanAddr() {
+		nv := reflect.New(v.Type())
+		nv.Elem().Set(v)
+		v = nv.Elem()
+	}
+
+	return v.Addr().Interface()
+}
+
+// formMarshaler returns the Marshaler of a value with methods m, if implemented by either the value or a pointer to it.
+func formMarshaler(v reflect.Value, m methods) (Marshaler, bool) {
+	if !m.pointer(methodMarshaler) {
+		return nil, false
+	}
+
+	return addressable(v, m, methodMarshaler).(Marshaler), true
+}
+
+// textUnmarshaler returns the encoding.TextUnmarshaler of an addressable value with methods m, if implemented.
+// time.Time is excluded as it has its own handling.
+func textUnmarshaler(v reflect.Value, m methods) (encoding.TextUnmarshaler, bool) {
+	if !m.pointer(methodTextUnmarshaler) || !v.CanAddr() {
+		return nil, false
+	}
+
+	return v.Addr().Interface().(encoding.TextUnmarshaler), true
+}
+
+// textMarshaler returns the encoding.TextMarshaler of a value with methods m, if implemented by either the value or a pointer to it.
+// time.Time is excluded as it has its own handling.
+func textMarshaler(v reflect.Value, m methods) (encoding.TextMarshaler, bool) {
+	if !m.pointer(methodTextMarshaler) {
+		return nil, false
+	}
+
+	return addressable(v, m, methodTextMarshaler).(encoding.TextMarshaler), true
+}
+
+// declares reports whether the type, or a pointer to it, implements the interface with methods of its own
+// rather than methods promoted from an embedded field, eg. a struct embedding time.Time is not a TextMarshaler
+// as its other fields would be lost.
+func declares(typ, iface reflect.Type) bool {
+	if !reflect.PointerTo(typ).Implements(iface) {
+		return false
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		if promoted(typ, iface.Method(i).Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// promoted reports whether the method of the struct type is promoted from an embedded field,
+// a method the struct declares itself, overriding it, is compiled as is while promoted methods are generated wrappers.
+func promoted(typ reflect.Type, name string) bool {
+	embeds := false
+	for i := 0; i < typ.NumField() && !embeds; i++ {
+		if f := typ.Field(i); f.Anonymous {
+			_, ok := reflect.PointerTo(f.Type).MethodByName(name)
+			if !ok {
+				_, ok = f.Type.MethodByName(name)
+			}
+
+			embeds = ok
+		}
+	}
+
+	if !embeds {
+		return false
+	}
+
+	m, ok := typ.MethodByName(name)
+	if !ok {
+		m, _ = reflect.PointerTo(typ).MethodByName(name)
+	}
+
+	pc := m.Func.Pointer()
+	file, _ := runtime.FuncForPC(pc).FileLine(pc)
+	return file == "<autogenerated>"
+}
+
+// unmarshalText decodes the text into v using its encoding.TextUnmarshaler,
+// a big.Float is given enough precision for every digit of the text rather than the default of 64 bits.
+func unmarshalText(tu encoding.TextUnmarshaler, v reflect.Value, text string) error {
+	if v.Type() != bigFloatType {
+		return tu.UnmarshalText([]byte(text))
+	}
+
+	f, err := parseBigFloat(text)
+	if err != nil {
+		return err
+	}
+
+	z := tu.(*big.Float)
+	z.SetPrec(f.Prec()).Set(f)
+	return nil
+}
+
+// maxBigFloatPrec caps the precision of a parsed big.Float, as parsing time grows faster than the number of digits.
+const maxBigFloatPrec = 1 << 16
+
+// parseBigFloat parses the text with enough precision for every digit of it, so decimal text
+// of a binary value, such as written by marshalText, is parsed exactly.
+func parseBigFloat(text string) (*big.Float, error) {
+	prec := uint(math.Ceil(float64(len(text)) * math.Log2(10)))
+	if prec < 64 {
+		prec = 64
+	} else if prec > maxBigFloatPrec {
+		return nil, ErrBigFloatPrecision
+	}
+
+	f, _, err := big.ParseFloat(text, 0, prec, big.ToNearestEven)
+	return f, err
+}
+
+// marshalText encodes the value using its encoding.TextMarshaler,
+// a big.Float is written without an exponent, with the shortest digits when they decode to the same value
+// and otherwise every digit of its exact value, which terminates as it is binary.
+func marshalText(tm encoding.TextMarshaler) ([]byte, error) {
+	f, ok := tm.(*big.Float)
+	if !ok {
+		return tm.MarshalText()
+	}
+
+	b := f.Append(nil, 'f', -1)
+	if f.IsInf() {
+		return b, nil
+	}
+
+	if p, err := parseBigFloat(string(b)); err == nil && p.Cmp(f) == 0 {
+		return b, nil
+	}
+
+	// f is a mantissa of MinPrec bits times 2**(exp-MinPrec), each negative power of 2 adds a decimal digit
+	digits := int(f.MinPrec()) - f.MantExp(nil)
+	if digits < 0 {
+		digits = 0
+	}
+
+	return f.Append(nil, 'f', digits), nil
+}
+
+// formatScalar returns the string form of a string or number value, following pointers.
+func formatScalar(current reflect.Value) (string, bool) {
+	v, kind := ExtractType(current)
+	switch kind {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	}
+
+	return "", false
+}
+
+// scalarNumber returns a number value as a float64 for comparison.
+func scalarNumber(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+
+	return 0
+}
+
+// valueStruct reports whether a struct type is decoded from and encoded to a single value
+// rather than by its fields eg. time.Time and url.URL.
+func valueStruct(typ reflect.Type) bool {
+	return typ == timeType || typ == urlType || typ == mailAddressType || sqlNull(typ)
+}
+
+// sqlNull reports whether the type is one of the database/sql Null types eg. sql.NullString or sql.Null[T],
+// a value followed by its Valid field.
+func sqlNull(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.NumField() == 2 && typ.PkgPath() == "database/sql" &&
+		strings.HasPrefix(typ.Name(), "Null") && typ.Field(1).Name == "Valid"
+}
+
+// scanner returns the sql.Scanner of an addressable value with methods m, if implemented.
+// The database/sql Null types are excluded as they have their own handling.
+func scanner(v reflect.Value, m methods) (sql.Scanner, bool) {
+	if !m.pointer(methodScanner) || !v.CanAddr() {
+		return nil, false
+	}
+
+	return v.Addr().Interface().(sql.Scanner), true
+}
+
+// valuer returns the driver.Valuer of a value with methods m, if implemented by either the value or a pointer to it.
+// The database/sql Null types are excluded as they have their own handling.
+func valuer(v reflect.Value, m methods) (driver.Valuer, bool) {
+	i, ok := implementer(v, m, methodValuer)
+	if !ok {
+		return nil, false
+	}
+
+	return i.(driver.Valuer), true
+}
+
+// parseValue parses the text into v, a url.URL or mail.Address.
+func parseValue(v reflect.Value, text string) error {
+	switch v.Type() {
+	case urlType:
+		u, err := url.Parse(text)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(*u))
+	case mailAddressType:
+		a, err := mail.ParseAddress(text)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(*a))
+	}
+
+	return nil
+}
+
+// formatValue formats v, a url.URL or mail.Address.
+func formatValue(v reflect.Value) string {
+	switch v.Type() {
+	case urlType:
+		u := v.Interface().(url.URL)
+		return u.String()
+	case mailAddressType:
+		a := v.Interface().(mail.Address)
+		return a.String()
+	}
+
+	return ""
+}
+
+// isUUID reports whether the text is in the canonical UUID form eg. 123e4567-e89b-12d3-a456-426614174000.
+func isUUID(text string) bool {
+	return len(text) == 36 && text[8] == '-' && text[13] == '-' && text[18] == '-' && text[23] == '-'
+}
+
+// parseByteArray decodes hex, or for 16 byte arrays canonical UUID text, into the byte array v.
+func parseByteArray(v reflect.Value, text string) error {
+	if v.Len() == 16 && isUUID(text) {
+		text = text[:8] + text[9:13] + text[14:18] + text[19:23] + text[24:]
+	}
+
+	if hex.DecodedLen(len(text)) != v.Len() {
+		return fmt.Errorf("expected %d hex encoded bytes", v.Len())
+	}
+
+	b, err := hex.DecodeString(text)
+	if err != nil {
+		return err
+	}
+
+	for i := range b {
+		v.Index(i).SetUint(uint64(b[i]))
+	}
+
+	return nil
+}
+
+// formatByteArray encodes the byte array v as hex, or for 16 byte arrays in the ByteArrayUUID format as canonical UUID text.
+func formatByteArray(v reflect.Value, format ByteArrayFormat) string {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+
+	s := hex.EncodeToString(b)
+	if format == ByteArrayUUID && len(b) == 16 {
+		return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+	}
+
+	return s
+}
+
+// parseBytes decodes the text of a byte slice in the format,
+// base64 may use either the standard or URL alphabet, with or without padding.
+func parseBytes(text string, format uint8) ([]byte, error) {
+	switch format {
+	case bytesBase64:
+		text = strings.TrimRight(text, "=")
+		if strings.ContainsAny(text, "-_") {
+			return base64.RawURLEncoding.DecodeString(text)
+		}
+
+		return base64.RawStdEncoding.DecodeString(text)
+	case bytesHex:
+		return hex.DecodeString(text)
+	default:
+		return []byte(text), nil
+	}
+}
+
+// formatBytes encodes a byte slice in the format, base64 using the standard alphabet with padding.
+func formatBytes(b []byte, format uint8) string {
+	switch format {
+	case bytesBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	case bytesHex:
+		return hex.EncodeToString(b)
+	default:
+		return string(b)
+	}
+}
+
+// splitValues splits each value on the delimiter, returning the parts of all values in order.
+func splitValues(vals []string, sep string) []string {
+	parts := make([]string, 0, len(vals))
+	for _, v := range vals {
+		parts = append(parts, strings.Split(v, sep)...)
+	}
+
+	return parts
+}
+
+const (
+	// keySpecial are the characters escaped in map keys and field names when key escaping is enabled.
+	keySpecial = `\.[]`
+	// emptyKey is the escaped empty map key, which would otherwise be written as empty brackets eg. Map[].
+	emptyKey = `\0`
+)
+
+// escapeKey escapes the characters of keySpecial in a map key or field name with a backslash,
+// the empty key is written as emptyKey.
+func escapeKey(s string) string {
+	if len(s) == 0 {
+		return emptyKey
+	}
+
+	if !strings.ContainsAny(s, keySpecial) {
+		return s
+	}
+
+	b := make([]byte, 0, len(s)+4)
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(keySpecial, s[i]) != -1 {
+			b = append(b, '\\')
+		}
+
+		b = append(b, s[i])
+	}
+
+	return string(b)
+}
+
+// unescapeKey removes the backslashes escapeKey adds.
+func unescapeKey(s string) string {
+	if s == emptyKey {
+		return ""
+	}
+
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+
+		b = append(b, s[i])
+	}
+
+	return string(b)
+}